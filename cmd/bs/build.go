@@ -1,16 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 
-	alist "github.com/gueckmooh/bs/pkg/adjacency_list"
 	"github.com/gueckmooh/bs/pkg/argparse"
 	"github.com/gueckmooh/bs/pkg/build"
 	"github.com/gueckmooh/bs/pkg/common/colors"
-	"github.com/gueckmooh/bs/pkg/fsutil"
 	log "github.com/gueckmooh/bs/pkg/logging"
 	"github.com/gueckmooh/bs/pkg/lua"
 	"github.com/gueckmooh/bs/pkg/project"
@@ -23,10 +25,19 @@ type BuildOptions struct {
 	buildUpstream *bool
 	directory     *string
 	alwaysBuild   *bool
+	explain       *bool
+	keepGoing     *bool
+	compdb        *bool
 	profile       *string
 	platform      *string
+	buildDir      *string
 	jobs          *int
 	guessJobs     *bool
+	watch         *bool
+	output        *string
+	sarif         *string
+	eventsOutput  io.Writer
+	ctx           context.Context
 }
 
 func (opts *BuildOptions) init(parser *argparse.Parser) {
@@ -40,28 +51,24 @@ func (opts *BuildOptions) init(parser *argparse.Parser) {
 		Required: false,
 		Help:     "Instruct to build all the upstream components",
 	})
-	opts.directory = opts.command.String("C", "directory", &argparse.Options{
-		Validate: func(args []string) error {
-			for _, s := range args {
-				if s == "" {
-					continue
-				}
-				stats, err := os.Stat(s)
-				if err != nil {
-					return err
-				}
-				if !stats.IsDir() {
-					return fmt.Errorf("%s is not a directory", s)
-				}
-			}
-			return nil
-		},
-		Help: "Change to directory dir before reading the bsfiles or doing anything else.",
-	})
+	opts.directory = addDirectoryOption(opts.command)
 	opts.alwaysBuild = opts.command.Flag("B", "always-build", &argparse.Options{
 		Required: false,
 		Help:     "Unconditionally build all targets.",
 	})
+	opts.explain = opts.command.Flag("", "explain", &argparse.Options{
+		Required: false,
+		Help:     "Explain why each target is rebuilt.",
+	})
+	opts.keepGoing = opts.command.Flag("k", "keep-going", &argparse.Options{
+		Required: false,
+		Help: `Keep building after a failure, only the targets depending
+on the failed ones are not built.`,
+	})
+	opts.compdb = opts.command.Flag("", "compdb", &argparse.Options{
+		Required: false,
+		Help:     "Write the compilation database of the built components.",
+	})
 	opts.profile = opts.command.String("p", "profile", &argparse.Options{
 		Required: false,
 		Help:     "Use selected profile for build.",
@@ -70,6 +77,7 @@ func (opts *BuildOptions) init(parser *argparse.Parser) {
 		Required: false,
 		Help:     "Use selected platform for build.",
 	})
+	opts.buildDir = addBuildDirectoryOption(opts.command)
 	opts.jobs = opts.command.Int("j", "jobs", &argparse.Options{
 		Required: false,
 		Help: `Specifies the number of jobs (commands) to run simultaneously.
@@ -79,98 +87,85 @@ If used with -J, this option takes precedence.`,
 		Required: false,
 		Help:     `Makes bs guess the number n of jobs to use as with -j n.`,
 	})
+	opts.watch = opts.command.Flag("w", "watch", &argparse.Options{
+		Required: false,
+		Help: `Keep running, and rebuild the components whose files change
+until interrupted.`,
+	})
+	opts.output = opts.command.Selector("", "output", []string{"text", "json"}, &argparse.Options{
+		Required: false,
+		Default:  "text",
+		Help: `Print the build as text, or as JSON events, one per line,
+on the standard output.`,
+	})
+	opts.sarif = opts.command.String("", "sarif", &argparse.Options{
+		Required: false,
+		Help:     "Write a SARIF report of the compiler diagnostics in the given file.",
+	})
 }
 
 func (opts *BuildOptions) happened() bool {
 	return opts.command.Happened()
 }
 
-func tryBuildMainInDirectory(directory string, opts Options) error {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-	log.Log.Printf("Movind to directory '%s'\n", directory)
-	err = os.Chdir(directory)
-	if err != nil {
-		return err
-	}
-	err = tryBuildMain(opts)
-	if err != nil {
-		return err
-	}
-	log.Log.Printf("Exiting directory '%s'\n", directory)
-	err = os.Chdir(cwd)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func tryBuildMain(opts Options) error {
-	cwd, err := os.Getwd()
+	C, proj, oldcwd, err := openProject()
 	if err != nil {
 		return err
 	}
+	defer C.Close()
 
-	projFile, err := fsutil.FindFileUpstream(project.ProjectConfigFile, cwd)
-	if err != nil {
-		return err
-	}
-	oldcwd := cwd
-	cwd = filepath.Dir(projFile)
-	err = os.Chdir(cwd)
-	if err != nil {
-		return err
+	ctbs := *opts.buildOptions.name
+	if len(ctbs) == 0 {
+		ctb, err := getDefaultComponent(proj, oldcwd)
+		if err != nil {
+			return err
+		}
+		ctbs = append(ctbs, ctb)
 	}
 
-	log.Debug.SetPrefix(fmt.Sprintf("%sDebug:%s ", colors.ColorPurple, colors.ColorReset))
-	log.Debug.Printf("Reading project...\n")
-
-	C := lua.NewLuaContext()
-	defer C.Close()
-	proj, err := C.GetProject(cwd)
+	if *opts.buildOptions.watch {
+		return watchProject(opts, C, proj, ctbs)
+	}
+	bops, err := getBuildOptions(opts, C, proj, *opts.buildOptions.buildUpstream)
 	if err != nil {
 		return err
 	}
-	log.Debug.Printf("Computing component dependencies in project %s\n", proj.Name)
-
-	err = proj.ComputeComponentDependencies()
+	builder, err := build.NewProjectBuilder(proj, ctbs, bops...)
 	if err != nil {
 		return err
 	}
+	return builder.Build()
+}
 
-	ctbs := *opts.buildOptions.name
-	if len(ctbs) == 0 {
-		if proj.DefaultTarget != "" {
-			ctbs = append(ctbs, proj.DefaultTarget)
-		} else {
-			compFile, err := fsutil.FindFileUpstream(project.ComponentConfigFile, oldcwd)
-			if err == nil {
-				ctb, err := proj.GetComponentByPath(filepath.Dir(compFile))
-				if err == nil {
-					ctbs = append(ctbs, ctb.Name)
-				}
-			}
-		}
-	}
-	if len(ctbs) == 0 {
-		return fmt.Errorf("No component name given")
-	}
-
-	if len(ctbs) > 1 {
-		if *opts.buildOptions.buildUpstream {
-			fmt.Fprintf(os.Stderr, "%sWarning%s: several components given, build upstream ignored\n",
-				colors.ColorYellow, colors.ColorReset)
-			*opts.buildOptions.buildUpstream = false
-		}
-	}
-
+// getBuildOptions returns the options of the project builder given on
+// the command line, the upstream components are built if buildUpstream
+// is set.
+func getBuildOptions(opts Options, C *lua.LuaContext, proj *project.Project, buildUpstream bool) ([]build.BuildOption, error) {
 	var bops []build.BuildOption
 	bops = append(bops, build.WithLuaContect(C))
+	bops = append(bops, build.WithContext(opts.buildOptions.ctx))
 	if *opts.buildOptions.alwaysBuild {
 		bops = append(bops, build.WithAlwaysBuild)
 	}
+	if buildUpstream {
+		bops = append(bops, build.WithBuildUpstream)
+	}
+	if *opts.buildOptions.explain {
+		bops = append(bops, build.WithExplain)
+	}
+	if *opts.buildOptions.keepGoing {
+		bops = append(bops, build.WithKeepGoing)
+	}
+	if *opts.buildOptions.compdb {
+		bops = append(bops, build.WithCompilationDatabase)
+	}
+	if *opts.buildOptions.output == "json" {
+		bops = append(bops, build.WithEventHandler(build.NewJSONEventHandler(opts.buildOptions.eventsOutput)))
+	}
+	if len(*opts.buildOptions.sarif) > 0 {
+		bops = append(bops, build.WithSARIFReport(*opts.buildOptions.sarif))
+	}
 	if *opts.buildOptions.jobs > 1 {
 		bops = append(bops, build.WithJobs(*opts.buildOptions.jobs))
 		if *opts.buildOptions.guessJobs {
@@ -181,94 +176,39 @@ func tryBuildMain(opts Options) error {
 		bops = append(bops, build.WithJobs(runtime.GOMAXPROCS(0)))
 		log.Log.Printf("%sInfo:%s using %d jobs\n", colors.ColorCyan, colors.ColorReset, runtime.GOMAXPROCS(0))
 	}
-	profilestr := "unspecified"
-	platformstr := "unspecified"
-	if *opts.buildOptions.profile != "" {
-		bops = append(bops, build.WithProfile(*opts.buildOptions.profile))
-		profilestr = *opts.buildOptions.profile
-	} else if proj.DefaultProfile != "" {
-		bops = append(bops, build.WithProfile(proj.DefaultProfile))
-		profilestr = proj.DefaultProfile
-	}
-	if *opts.buildOptions.platform != "" {
-		bops = append(bops, build.WithPlatform(*opts.buildOptions.platform))
-		platformstr = *opts.buildOptions.platform
-	} else if proj.DefaultPlatform != "" {
-		bops = append(bops, build.WithPlatform(proj.DefaultPlatform))
-		platformstr = proj.DefaultPlatform
-	}
-
-	log.Log.Printf("%sInfo:%s build configured for %s profile, %s platform...\n",
-		colors.ColorCyan, colors.ColorReset, profilestr, platformstr)
-
-	if *opts.buildOptions.buildUpstream {
-		err = BuildUpstream(proj, ctbs[0], bops)
-		if err != nil {
-			return err
-		}
-	} else {
-		for _, ctb := range ctbs {
-			builder, err := build.NewBuilder(proj, ctb, bops...)
-			if err != nil {
-				return err
-			}
-			err = builder.BuildComponent()
-			if err != nil {
-				return err
-			}
-		}
+	profileBops, err := getProfileBuildOptions(proj,
+		*opts.buildOptions.profile, *opts.buildOptions.platform, *opts.buildOptions.buildDir)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return append(bops, profileBops...), nil
 }
 
-func BuildUpstream(proj *project.Project, ctb string, bops []build.BuildOption) error {
-	var processNode func(alist.VertexDescriptor) error
-	g := proj.ComponentDeps.G
-	builtComponent := make(map[alist.VertexDescriptor]bool)
-	processNode = func(v alist.VertexDescriptor) error {
-		oe, err := g.OutEdges(v)
-		if err != nil {
-			return err
-		}
-
-		for _, ed := range oe {
-			target, err := g.Target(ed)
-			if err != nil {
-				return err
-			}
-			err = processNode(target)
-			if err != nil {
-				return err
-			}
-		}
-
-		if _, ok := builtComponent[v]; ok {
-			return nil
-		}
-		builtComponent[v] = true
-
-		builder, err := build.NewBuilder(proj, g.GetVertexAttribute(v).Name, bops...)
-		if err != nil {
-			return err
-		}
-		err = builder.BuildComponent()
+func buildMain(opts Options) error {
+	// The build is stopped on the first interruption, the next one kills
+	// bs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	opts.buildOptions.ctx = ctx
+	opts.buildOptions.eventsOutput = os.Stdout
+	if *opts.buildOptions.output == "json" {
+		// Everything but the events is printed on the standard error, so
+		// that the standard output can be parsed
+		log.SetOutput(os.Stderr)
+	}
+	var err error
+	if len(*opts.buildOptions.sarif) > 0 {
+		*opts.buildOptions.sarif, err = filepath.Abs(*opts.buildOptions.sarif)
 		if err != nil {
 			return err
 		}
-		return nil
 	}
-	c, err := proj.GetComponent(ctb)
-	if err != nil {
-		return err
-	}
-	return processNode(proj.ComponentDeps.Vmap[c])
-}
-
-func buildMain(opts Options) error {
-	var err error
 	if len(*opts.buildOptions.directory) > 0 {
-		err = tryBuildMainInDirectory(*opts.buildOptions.directory, opts)
+		err = inDirectory(*opts.buildOptions.directory, func() error { return tryBuildMain(opts) })
 	} else {
 		err = tryBuildMain(opts)
 	}
@@ -2,66 +2,231 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/gueckmooh/bs/pkg/argparse"
-	"github.com/gueckmooh/bs/pkg/fsutil"
-	"github.com/gueckmooh/bs/pkg/lua"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/common/colors"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/git"
 	"github.com/gueckmooh/bs/pkg/project"
 )
 
 type CleanOptions struct {
 	command *argparse.Command
 
-	name *argparse.PosStringResult
+	name      *argparse.PosStringResult
+	upstream  *bool
+	dryRun    *bool
+	distclean *bool
+	directory *string
+	profile   *string
+	platform  *string
+	buildDir  *string
 }
 
 func (opts *CleanOptions) init(parser *argparse.Parser) {
 	opts.command = parser.NewCommand("clean", "Clean project or component")
+
+	opts.name = opts.command.PosString("component", &argparse.Options{
+		Required: false,
+		Help:     "The name of the component to clean",
+	})
+	opts.upstream = opts.command.Flag("", "upstream", &argparse.Options{
+		Required: false,
+		Help:     "Also clean the components required by the given ones.",
+	})
+	opts.dryRun = opts.command.Flag("n", "dry-run", &argparse.Options{
+		Required: false,
+		Help:     "Print what would be removed without removing it.",
+	})
+	opts.distclean = opts.command.Flag("", "distclean", &argparse.Options{
+		Required: false,
+		Help:     "Also remove the repositories cloned by the prebuild actions.",
+	})
+	opts.directory = addDirectoryOption(opts.command)
+	opts.profile = opts.command.String("p", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Only clean the build of the selected profile.",
+	})
+	opts.platform = opts.command.String("P", "platform", &argparse.Options{
+		Required: false,
+		Help:     "Only clean the build of the selected platform.",
+	})
+	opts.buildDir = opts.command.String("", "build-dir", &argparse.Options{
+		Required: false,
+		Help:     "Only clean the build put in dir.",
+	})
 }
 
 func (opts *CleanOptions) happened() bool {
 	return opts.command.Happened()
 }
 
-func tryCleanMain(opts Options) error {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
+// getCleanedComponents returns the components named names, along with
+// the components they require if upstream is set.
+func getCleanedComponents(proj *project.Project, names []string, upstream bool) ([]*project.Component, error) {
+	var components []*project.Component
+	for _, name := range names {
+		c, err := proj.GetComponent(name)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+		if upstream {
+			components = append(components, c.Dependencies...)
+		}
 	}
+	return functional.ListUniq(components), nil
+}
 
-	projFile, err := fsutil.FindFileUpstream(project.ProjectConfigFile, cwd)
+// getComponentsOutputs returns the outputs of the components in the
+// selected build directory, or in the ones of all the configurations if
+// none is selected.
+func getComponentsOutputs(proj *project.Project, components []*project.Component, selected bool) ([]string, error) {
+	configurations := []string{proj.Config.ConfigurationDirectory}
+	if !selected {
+		names, err := proj.Config.GetConfigurationNames()
+		if err != nil {
+			return nil, err
+		}
+		configurations = append(configurations, names...)
+	}
+	var outputs []string
+	for _, configuration := range configurations {
+		proj.Config.ConfigurationDirectory = configuration
+		for _, c := range components {
+			outputs = append(outputs, build.GetComponentOutputs(proj, c)...)
+		}
+	}
+	return outputs, nil
+}
+
+// getRootConfigurationOutputs returns the outputs of the build with the
+// default profile and no platform, which are in the build root along with
+// the directories of the other configurations.
+func getRootConfigurationOutputs(proj *project.Project) ([]string, error) {
+	entries, err := ioutil.ReadDir(proj.Config.GetBuildRootDirectory(false))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	configurations, err := proj.Config.GetConfigurationNames()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var outputs []string
+	for _, entry := range entries {
+		if !functional.ListIn(configurations, entry.Name()) {
+			outputs = append(outputs, filepath.Join(proj.Config.GetBuildRootDirectory(true), entry.Name()))
+		}
+	}
+	return outputs, nil
+}
+
+// getCheckouts returns the repositories cloned by bs in the directories
+// of the components, or in the whole project if there are none.
+func getCheckouts(proj *project.Project, components []*project.Component) ([]string, error) {
+	dirs := []string{proj.Config.ProjectRootDirectory}
+	if len(components) > 0 {
+		dirs = functional.ListMap(components, func(c *project.Component) string { return c.Path })
 	}
+	var checkouts []string
+	for _, dir := range dirs {
+		found, err := git.FindCheckouts(dir, proj.Config.GetBuildRootDirectory(false))
+		if err != nil {
+			return nil, err
+		}
+		for _, checkout := range found {
+			if rel, err := filepath.Rel(proj.Config.ProjectRootDirectory, checkout); err == nil {
+				checkout = rel
+			}
+			checkouts = append(checkouts, checkout)
+		}
+	}
+	return checkouts, nil
+}
 
-	cwd = filepath.Dir(projFile)
-	err = os.Chdir(cwd)
+func tryCleanMain(opts Options) error {
+	C, proj, _, err := openProject()
 	if err != nil {
 		return err
 	}
-
-	C := lua.NewLuaContext()
 	defer C.Close()
-	proj, err := C.GetProject(cwd)
+
+	selected := true
+	if *opts.cleanOptions.buildDir != "" {
+		proj.Config.SetBuildDirectory(*opts.cleanOptions.buildDir)
+	} else if *opts.cleanOptions.profile != "" || *opts.cleanOptions.platform != "" {
+		proj.Config.SetConfiguration(*opts.cleanOptions.profile, *opts.cleanOptions.platform)
+	} else {
+		selected = false
+	}
+	err = proj.Config.Check()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Removing dir %s\n", proj.Config.GetBuildDirectory(true))
-	err = os.RemoveAll(proj.Config.GetBuildDirectory(true))
+	components, err := getCleanedComponents(proj, *opts.cleanOptions.name, *opts.cleanOptions.upstream)
 	if err != nil {
 		return err
 	}
+	var paths []string
+	if len(components) > 0 {
+		paths, err = getComponentsOutputs(proj, components, selected)
+		if err != nil {
+			return err
+		}
+	} else if selected && proj.Config.ConfigurationDirectory == "" && *opts.cleanOptions.buildDir == "" {
+		paths, err = getRootConfigurationOutputs(proj)
+		if err != nil {
+			return err
+		}
+	} else if selected {
+		paths = []string{proj.Config.GetBuildDirectory(true)}
+	} else {
+		// Without any configuration selected, the builds of all of them
+		// are removed
+		paths = []string{proj.Config.GetBuildRootDirectory(true)}
+	}
+	if *opts.cleanOptions.distclean {
+		checkouts, err := getCheckouts(proj, components)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, checkouts...)
+	}
+
+	for _, path := range paths {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		if *opts.cleanOptions.dryRun {
+			fmt.Printf("Would remove %s%s%s\n", colors.StyleBold, path, colors.StyleReset)
+			continue
+		}
+		fmt.Printf("Removing %s%s%s\n", colors.StyleBold, path, colors.StyleReset)
+		err = os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func cleanMain(opts Options) error {
-	err := tryCleanMain(opts)
+	var err error
+	if len(*opts.cleanOptions.directory) > 0 {
+		err = inDirectory(*opts.cleanOptions.directory, func() error { return tryCleanMain(opts) })
+	} else {
+		err = tryCleanMain(opts)
+	}
 	if err != nil {
-		return fmt.Errorf("Error while building components:\n  %s", err.Error())
+		return fmt.Errorf("Error while cleaning:\n  %s", err.Error())
 	}
 
 	return nil
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gueckmooh/bs/pkg/argparse"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+type CompdbOptions struct {
+	command *argparse.Command
+
+	name      *argparse.PosStringResult
+	directory *string
+	profile   *string
+	platform  *string
+	buildDir  *string
+}
+
+func (opts *CompdbOptions) init(parser *argparse.Parser) {
+	opts.command = parser.NewCommand("compdb", "Write the compilation database of the project")
+
+	opts.name = opts.command.PosString("component", &argparse.Options{
+		Required: false,
+		Help:     "The name of the component to write the compile commands of, all by default",
+	})
+	opts.directory = addDirectoryOption(opts.command)
+	opts.profile = opts.command.String("p", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Use selected profile.",
+	})
+	opts.platform = opts.command.String("P", "platform", &argparse.Options{
+		Required: false,
+		Help:     "Use selected platform.",
+	})
+	opts.buildDir = addBuildDirectoryOption(opts.command)
+}
+
+func (opts *CompdbOptions) happened() bool {
+	return opts.command.Happened()
+}
+
+func tryCompdbMain(opts Options) error {
+	C, proj, _, err := openProject()
+	if err != nil {
+		return err
+	}
+	defer C.Close()
+
+	ctbs := *opts.compdbOptions.name
+	if len(ctbs) == 0 {
+		ctbs = functional.ListMap(proj.Components, func(c *project.Component) string {
+			return c.Name
+		})
+	}
+
+	var bops []build.BuildOption
+	bops = append(bops, build.WithLuaContect(C))
+	profileBops, err := getProfileBuildOptions(proj,
+		*opts.compdbOptions.profile, *opts.compdbOptions.platform, *opts.compdbOptions.buildDir)
+	if err != nil {
+		return err
+	}
+	bops = append(bops, profileBops...)
+
+	builder, err := build.NewProjectBuilder(proj, ctbs, bops...)
+	if err != nil {
+		return err
+	}
+	return builder.WriteCompilationDatabase()
+}
+
+func compdbMain(opts Options) error {
+	var err error
+	if len(*opts.compdbOptions.directory) > 0 {
+		err = inDirectory(*opts.compdbOptions.directory, func() error { return tryCompdbMain(opts) })
+	} else {
+		err = tryCompdbMain(opts)
+	}
+	if err != nil {
+		return fmt.Errorf("Error while writing the compilation database:\n  %s", err.Error())
+	}
+
+	return nil
+}
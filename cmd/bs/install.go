@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/gueckmooh/bs/pkg/argparse"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+type InstallOptions struct {
+	command *argparse.Command
+
+	name       *argparse.PosStringResult
+	directory  *string
+	profile    *string
+	platform   *string
+	buildDir   *string
+	jobs       *int
+	guessJobs  *bool
+	prefix     *string
+	destDir    *string
+	binDir     *string
+	libDir     *string
+	includeDir *string
+}
+
+func (opts *InstallOptions) init(parser *argparse.Parser) {
+	opts.command = parser.NewCommand("install", "Build and install project or component")
+
+	opts.name = opts.command.PosString("component", &argparse.Options{
+		Required: false,
+		Help:     "The name of the component to install with the components it requires, all by default",
+	})
+	opts.directory = addDirectoryOption(opts.command)
+	opts.profile = opts.command.String("p", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Use selected profile for build.",
+	})
+	opts.platform = opts.command.String("P", "platform", &argparse.Options{
+		Required: false,
+		Help:     "Use selected platform for build.",
+	})
+	opts.buildDir = addBuildDirectoryOption(opts.command)
+	opts.jobs = opts.command.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Help:     `Specifies the number of jobs (commands) to run simultaneously.`,
+	})
+	opts.guessJobs = opts.command.Flag("J", "guess-jobs", &argparse.Options{
+		Required: false,
+		Help:     `Makes bs guess the number n of jobs to use as with -j n.`,
+	})
+	opts.prefix = opts.command.String("", "prefix", &argparse.Options{
+		Required: false,
+		Default:  build.DefaultInstallPrefix,
+		Help:     "Install the files in the given directory.",
+	})
+	opts.destDir = opts.command.String("", "destdir", &argparse.Options{
+		Required: false,
+		Help:     "Stage the installation in the given directory, DESTDIR by default.",
+	})
+	opts.binDir = opts.command.String("", "bindir", &argparse.Options{
+		Required: false,
+		Default:  build.DefaultInstallBinDir,
+		Help:     "Install the executables in the given directory of the prefix.",
+	})
+	opts.libDir = opts.command.String("", "libdir", &argparse.Options{
+		Required: false,
+		Default:  build.DefaultInstallLibDir,
+		Help:     "Install the libraries in the given directory of the prefix.",
+	})
+	opts.includeDir = opts.command.String("", "includedir", &argparse.Options{
+		Required: false,
+		Default:  build.DefaultInstallIncludeDir,
+		Help:     "Install the exported headers in the given directory of the prefix.",
+	})
+}
+
+func (opts *InstallOptions) happened() bool {
+	return opts.command.Happened()
+}
+
+func tryInstallMain(opts Options) error {
+	destDir := *opts.installOptions.destDir
+	if destDir == "" {
+		destDir = os.Getenv("DESTDIR")
+	}
+	if destDir != "" {
+		var err error
+		destDir, err = filepath.Abs(destDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	C, proj, _, err := openProject()
+	if err != nil {
+		return err
+	}
+	defer C.Close()
+
+	ctbs := *opts.installOptions.name
+	if len(ctbs) == 0 {
+		for _, c := range proj.Components {
+			if c.Type != project.TypeTest {
+				ctbs = append(ctbs, c.Name)
+			}
+		}
+	}
+
+	var bops []build.BuildOption
+	bops = append(bops, build.WithLuaContect(C))
+	bops = append(bops, build.WithBuildUpstream)
+	if *opts.installOptions.jobs > 1 {
+		bops = append(bops, build.WithJobs(*opts.installOptions.jobs))
+	} else if *opts.installOptions.guessJobs {
+		bops = append(bops, build.WithJobs(runtime.GOMAXPROCS(0)))
+	}
+	profileBops, err := getProfileBuildOptions(proj,
+		*opts.installOptions.profile, *opts.installOptions.platform, *opts.installOptions.buildDir)
+	if err != nil {
+		return err
+	}
+	bops = append(bops, profileBops...)
+
+	builder, err := build.NewProjectBuilder(proj, ctbs, bops...)
+	if err != nil {
+		return err
+	}
+	err = builder.Build()
+	if err != nil {
+		return err
+	}
+
+	return builder.Install(
+		build.WithPrefix(*opts.installOptions.prefix),
+		build.WithDestDir(destDir),
+		build.WithBinDir(*opts.installOptions.binDir),
+		build.WithLibDir(*opts.installOptions.libDir),
+		build.WithIncludeDir(*opts.installOptions.includeDir))
+}
+
+func installMain(opts Options) error {
+	var err error
+	if len(*opts.installOptions.directory) > 0 {
+		err = inDirectory(*opts.installOptions.directory, func() error { return tryInstallMain(opts) })
+	} else {
+		err = tryInstallMain(opts)
+	}
+	if err != nil {
+		return fmt.Errorf("Error while installing components:\n  %s", err.Error())
+	}
+
+	return nil
+}
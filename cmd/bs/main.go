@@ -18,8 +18,12 @@ type Options struct {
 	verbose *bool
 	version *bool
 
-	buildOptions BuildOptions
-	cleanOptions CleanOptions
+	buildOptions   BuildOptions
+	cleanOptions   CleanOptions
+	compdbOptions  CompdbOptions
+	testOptions    TestOptions
+	runOptions     RunOptions
+	installOptions InstallOptions
 }
 
 func (opts *Options) init() {
@@ -38,16 +42,25 @@ func (opts *Options) init() {
 	})
 	opts.buildOptions.init(opts.parser)
 	opts.cleanOptions.init(opts.parser)
+	opts.compdbOptions.init(opts.parser)
+	opts.testOptions.init(opts.parser)
+	opts.runOptions.init(opts.parser)
+	opts.installOptions.init(opts.parser)
 }
 
 func tryMain() error {
 	var opts Options
 	opts.init()
 
-	err := opts.parser.Parse(os.Args)
+	args, runArgs := splitRunArguments(os.Args)
+	err := opts.parser.Parse(args)
 	if err != nil {
 		return fmt.Errorf("Fails to parse options:\n  %s\n", err.Error())
 	}
+	if runArgs != nil && !opts.runOptions.happened() {
+		return fmt.Errorf("Fails to parse options:\n  arguments after -- are only accepted by run\n")
+	}
+	opts.runOptions.args = runArgs
 
 	if *opts.version {
 		v, err := version.GetVersion()
@@ -71,6 +84,14 @@ func tryMain() error {
 		return buildMain(opts)
 	} else if opts.cleanOptions.happened() {
 		return cleanMain(opts)
+	} else if opts.compdbOptions.happened() {
+		return compdbMain(opts)
+	} else if opts.testOptions.happened() {
+		return testMain(opts)
+	} else if opts.runOptions.happened() {
+		return runMain(opts)
+	} else if opts.installOptions.happened() {
+		return installMain(opts)
 	}
 
 	return fmt.Errorf("No command given")
@@ -82,7 +103,7 @@ func String(s string) *string {
 
 func main() {
 	if err := tryMain(); err != nil {
-		fmt.Printf("Fatal error:\n%s\n", err.Error())
+		log.Info.Printf("Fatal error:\n%s\n", err.Error())
 		os.Exit(1)
 	}
 }
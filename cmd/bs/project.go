@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gueckmooh/bs/pkg/argparse"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/common/colors"
+	"github.com/gueckmooh/bs/pkg/fsutil"
+	log "github.com/gueckmooh/bs/pkg/logging"
+	"github.com/gueckmooh/bs/pkg/lua"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func addDirectoryOption(command *argparse.Command) *string {
+	return command.String("C", "directory", &argparse.Options{
+		Validate: func(args []string) error {
+			for _, s := range args {
+				if s == "" {
+					continue
+				}
+				stats, err := os.Stat(s)
+				if err != nil {
+					return err
+				}
+				if !stats.IsDir() {
+					return fmt.Errorf("%s is not a directory", s)
+				}
+			}
+			return nil
+		},
+		Help: "Change to directory dir before reading the bsfiles or doing anything else.",
+	})
+}
+
+// inDirectory runs f from directory.
+func inDirectory(directory string, f func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	log.Log.Printf("Movind to directory '%s'\n", directory)
+	err = os.Chdir(directory)
+	if err != nil {
+		return err
+	}
+	err = f()
+	if err != nil {
+		return err
+	}
+	log.Log.Printf("Exiting directory '%s'\n", directory)
+	err = os.Chdir(cwd)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// openProject moves to the root of the project containing the current
+// directory, then reads the project and computes the dependencies of its
+// components. The directory the command was run from is returned along
+// with the project, the lua context must be closed by the caller.
+func openProject() (*lua.LuaContext, *project.Project, string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	projFile, err := fsutil.FindFileUpstream(project.ProjectConfigFile, cwd)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	oldcwd := cwd
+	cwd = filepath.Dir(projFile)
+	err = os.Chdir(cwd)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	log.Debug.SetPrefix(fmt.Sprintf("%sDebug:%s ", colors.ColorPurple, colors.ColorReset))
+	log.Debug.Printf("Reading project...\n")
+
+	C := lua.NewLuaContext()
+	proj, err := C.GetProject(cwd)
+	if err != nil {
+		C.Close()
+		return nil, nil, "", err
+	}
+	log.Debug.Printf("Computing component dependencies in project %s\n", proj.Name)
+
+	err = proj.ComputeComponentDependencies()
+	if err != nil {
+		C.Close()
+		return nil, nil, "", err
+	}
+	return C, proj, oldcwd, nil
+}
+
+// getDefaultComponent returns the default target of the project, or the
+// component containing the directory dir if there is none.
+func getDefaultComponent(proj *project.Project, dir string) (string, error) {
+	if proj.DefaultTarget != "" {
+		return proj.DefaultTarget, nil
+	}
+	compFile, err := fsutil.FindFileUpstream(project.ComponentConfigFile, dir)
+	if err == nil {
+		ctb, err := proj.GetComponentByPath(filepath.Dir(compFile))
+		if err == nil {
+			return ctb.Name, nil
+		}
+	}
+	return "", fmt.Errorf("No component name given")
+}
+
+// getRunEnvironment returns the environment in which the executables of
+// the project are run, the shared libraries of the project being on the
+// loader path.
+func getRunEnvironment(proj *project.Project) []string {
+	libPath := proj.Config.GetLibDirectory(false)
+	if old := os.Getenv("LD_LIBRARY_PATH"); old != "" {
+		libPath = libPath + string(os.PathListSeparator) + old
+	}
+	return append(os.Environ(), "LD_LIBRARY_PATH="+libPath)
+}
+
+func addBuildDirectoryOption(command *argparse.Command) *string {
+	return command.String("", "build-dir", &argparse.Options{
+		Required: false,
+		Help: `Put the outputs of the build in dir instead of the build directory
+of the selected profile and platform.`,
+	})
+}
+
+// getProfileBuildOptions returns the build options selecting profile and
+// platform, or the default ones of the project if they are empty. The
+// outputs of the project are put in the build directory of the selected
+// configuration, or in buildDir if it is not empty.
+func getProfileBuildOptions(proj *project.Project, profile, platform, buildDir string) ([]build.BuildOption, error) {
+	var bops []build.BuildOption
+	if profile == "" {
+		profile = proj.DefaultProfile
+	}
+	if platform == "" {
+		platform = proj.DefaultPlatform
+	}
+	profilestr := "unspecified"
+	platformstr := "unspecified"
+	if profile != "" {
+		bops = append(bops, build.WithProfile(profile))
+		profilestr = profile
+	}
+	if platform != "" {
+		bops = append(bops, build.WithPlatform(platform))
+		platformstr = platform
+	}
+	if buildDir != "" {
+		proj.Config.SetBuildDirectory(buildDir)
+	} else {
+		proj.Config.SetConfiguration(profile, platform)
+	}
+	err := proj.Config.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Log.Printf("%sInfo:%s build configured for %s profile, %s platform in %s...\n",
+		colors.ColorCyan, colors.ColorReset, profilestr, platformstr, proj.Config.GetBuildDirectory(true))
+	return bops, nil
+}
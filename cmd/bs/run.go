@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+
+	"github.com/gueckmooh/bs/pkg/argparse"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+type RunOptions struct {
+	command *argparse.Command
+
+	name      *argparse.PosStringResult
+	directory *string
+	profile   *string
+	platform  *string
+	buildDir  *string
+	jobs      *int
+	guessJobs *bool
+
+	// args are the arguments given after --, passed to the executable
+	args []string
+}
+
+func (opts *RunOptions) init(parser *argparse.Parser) {
+	opts.command = parser.NewCommand("run", "Build and run an executable component, bs run <component> -- args...")
+
+	opts.name = opts.command.PosString("component", &argparse.Options{
+		Required: false,
+		Help:     "The name of the component to run",
+	})
+	opts.directory = addDirectoryOption(opts.command)
+	opts.profile = opts.command.String("p", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Use selected profile for build.",
+	})
+	opts.platform = opts.command.String("P", "platform", &argparse.Options{
+		Required: false,
+		Help:     "Use selected platform for build.",
+	})
+	opts.buildDir = addBuildDirectoryOption(opts.command)
+	opts.jobs = opts.command.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Help:     `Specifies the number of jobs (commands) to run simultaneously.`,
+	})
+	opts.guessJobs = opts.command.Flag("J", "guess-jobs", &argparse.Options{
+		Required: false,
+		Help:     `Makes bs guess the number n of jobs to use as with -j n.`,
+	})
+}
+
+func (opts *RunOptions) happened() bool {
+	return opts.command.Happened()
+}
+
+// splitRunArguments returns the arguments before the first --, parsed by
+// bs, and the ones after it.
+func splitRunArguments(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+func tryRunMain(opts Options) error {
+	C, proj, oldcwd, err := openProject()
+	if err != nil {
+		return err
+	}
+	defer C.Close()
+
+	names := *opts.runOptions.name
+	if len(names) > 1 {
+		return fmt.Errorf("Only one component can be run, arguments must be given after --")
+	}
+	var name string
+	if len(names) == 1 {
+		name = names[0]
+	} else {
+		name, err = getDefaultComponent(proj, oldcwd)
+		if err != nil {
+			return err
+		}
+	}
+	c, err := proj.GetComponent(name)
+	if err != nil {
+		return err
+	}
+	if !c.IsExecutable() {
+		return fmt.Errorf("Component '%s' is not an executable", c.Name)
+	}
+
+	var bops []build.BuildOption
+	bops = append(bops, build.WithLuaContect(C))
+	bops = append(bops, build.WithBuildUpstream)
+	if *opts.runOptions.jobs > 1 {
+		bops = append(bops, build.WithJobs(*opts.runOptions.jobs))
+	} else if *opts.runOptions.guessJobs {
+		bops = append(bops, build.WithJobs(runtime.GOMAXPROCS(0)))
+	}
+	profileBops, err := getProfileBuildOptions(proj,
+		*opts.runOptions.profile, *opts.runOptions.platform, *opts.runOptions.buildDir)
+	if err != nil {
+		return err
+	}
+	bops = append(bops, profileBops...)
+
+	builder, err := build.NewProjectBuilder(proj, []string{c.Name}, bops...)
+	if err != nil {
+		return err
+	}
+	err = builder.Build()
+	if err != nil {
+		return err
+	}
+
+	executable := filepath.Join(proj.Config.GetTargetDirectory(c.Type, false),
+		c.GetTargetName(project.LinkageUnspecified))
+	err = os.Chdir(oldcwd)
+	if err != nil {
+		return err
+	}
+	// The executable replaces bs, so that its exit code and the signals
+	// sent to it are the ones of the command
+	argv := append([]string{executable}, opts.runOptions.args...)
+	err = syscall.Exec(executable, argv, getRunEnvironment(proj))
+	return fmt.Errorf("Could not run '%s':\n\t%s", executable, err.Error())
+}
+
+func runMain(opts Options) error {
+	var err error
+	if len(*opts.runOptions.directory) > 0 {
+		err = inDirectory(*opts.runOptions.directory, func() error { return tryRunMain(opts) })
+	} else {
+		err = tryRunMain(opts)
+	}
+	if err != nil {
+		return fmt.Errorf("Error while running component:\n  %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/argparse"
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/project"
+	"github.com/gueckmooh/bs/pkg/testrunner"
+)
+
+type TestOptions struct {
+	command *argparse.Command
+
+	pattern   *argparse.PosStringResult
+	directory *string
+	profile   *string
+	platform  *string
+	buildDir  *string
+	jobs      *int
+	timeout   *int
+	junit     *string
+}
+
+func (opts *TestOptions) init(parser *argparse.Parser) {
+	opts.command = parser.NewCommand("test", "Build and run the tests of the project")
+
+	opts.pattern = opts.command.PosString("pattern", &argparse.Options{
+		Required: false,
+		Help:     "Only run the test components whose name matches the shell pattern",
+	})
+	opts.directory = addDirectoryOption(opts.command)
+	opts.profile = opts.command.String("p", "profile", &argparse.Options{
+		Required: false,
+		Help:     "Use selected profile for build.",
+	})
+	opts.platform = opts.command.String("P", "platform", &argparse.Options{
+		Required: false,
+		Help:     "Use selected platform for build.",
+	})
+	opts.buildDir = addBuildDirectoryOption(opts.command)
+	opts.jobs = opts.command.Int("j", "jobs", &argparse.Options{
+		Required: false,
+		Help:     "Specifies the number of jobs used to build and of tests run simultaneously, one per CPU by default.",
+	})
+	opts.timeout = opts.command.Int("t", "timeout", &argparse.Options{
+		Required: false,
+		Default:  300,
+		Help:     "Kill each test running for more than the given number of seconds, 0 to disable.",
+	})
+	opts.junit = opts.command.String("", "junit", &argparse.Options{
+		Required: false,
+		Help:     "Write a JUnit XML report of the tests in the given file.",
+	})
+}
+
+func (opts *TestOptions) happened() bool {
+	return opts.command.Happened()
+}
+
+// getTestComponents returns the test components of the project whose
+// name matches one of the patterns, all of them if none is given.
+func getTestComponents(proj *project.Project, patterns []string) ([]*project.Component, error) {
+	var tests []*project.Component
+	for _, c := range proj.Components {
+		if c.Type != project.TypeTest {
+			continue
+		}
+		matches := len(patterns) == 0
+		for _, pattern := range patterns {
+			ok, err := filepath.Match(pattern, c.Name)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid test pattern '%s': %s", pattern, err.Error())
+			}
+			matches = matches || ok
+		}
+		if matches {
+			tests = append(tests, c)
+		}
+	}
+	if len(tests) == 0 {
+		if len(patterns) > 0 {
+			return nil, fmt.Errorf("No test component matching '%s'", strings.Join(patterns, "', '"))
+		}
+		return nil, fmt.Errorf("No test component in project")
+	}
+	return tests, nil
+}
+
+func tryTestMain(opts Options) error {
+	junit := *opts.testOptions.junit
+	if len(junit) > 0 {
+		var err error
+		junit, err = filepath.Abs(junit)
+		if err != nil {
+			return err
+		}
+	}
+
+	C, proj, _, err := openProject()
+	if err != nil {
+		return err
+	}
+	defer C.Close()
+
+	components, err := getTestComponents(proj, *opts.testOptions.pattern)
+	if err != nil {
+		return err
+	}
+
+	jobs := *opts.testOptions.jobs
+	if jobs < 1 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+
+	var bops []build.BuildOption
+	bops = append(bops, build.WithLuaContect(C))
+	bops = append(bops, build.WithBuildUpstream)
+	bops = append(bops, build.WithJobs(jobs))
+	profileBops, err := getProfileBuildOptions(proj,
+		*opts.testOptions.profile, *opts.testOptions.platform, *opts.testOptions.buildDir)
+	if err != nil {
+		return err
+	}
+	bops = append(bops, profileBops...)
+
+	var names []string
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+	builder, err := build.NewProjectBuilder(proj, names, bops...)
+	if err != nil {
+		return err
+	}
+	err = builder.Build()
+	if err != nil {
+		return err
+	}
+
+	env := getRunEnvironment(proj)
+	var tests []*testrunner.Test
+	for _, c := range components {
+		tests = append(tests, &testrunner.Test{
+			Name:    c.Name,
+			Command: filepath.Join(proj.Config.GetTargetDirectory(c.Type, false), c.GetTargetName(project.LinkageUnspecified)),
+			Dir:     c.Path,
+			Env:     env,
+		})
+	}
+
+	fmt.Printf("--------------- Running %d tests...\n", len(tests))
+	results := testrunner.Run(tests,
+		testrunner.WithJobs(jobs),
+		testrunner.WithTimeout(time.Duration(*opts.testOptions.timeout)*time.Second),
+		testrunner.WithOutput(os.Stdout))
+	testrunner.PrintSummary(os.Stdout, results)
+
+	if len(junit) > 0 {
+		err = testrunner.WriteJUnitReport(junit, proj.Name, results)
+		if err != nil {
+			return err
+		}
+		builder.Emit(&build.Event{Kind: build.EventFileWritten, File: junit})
+	}
+
+	if failed := testrunner.Failed(results); len(failed) > 0 {
+		return fmt.Errorf("%d of %d tests failed", len(failed), len(results))
+	}
+	return nil
+}
+
+func testMain(opts Options) error {
+	var err error
+	if len(*opts.testOptions.directory) > 0 {
+		err = inDirectory(*opts.testOptions.directory, func() error { return tryTestMain(opts) })
+	} else {
+		err = tryTestMain(opts)
+	}
+	if err != nil {
+		return fmt.Errorf("Error while testing components:\n  %s", err.Error())
+	}
+
+	return nil
+}
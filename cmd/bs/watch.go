@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gueckmooh/bs/pkg/build"
+	"github.com/gueckmooh/bs/pkg/common/colors"
+	"github.com/gueckmooh/bs/pkg/functional"
+	log "github.com/gueckmooh/bs/pkg/logging"
+	"github.com/gueckmooh/bs/pkg/lua"
+	"github.com/gueckmooh/bs/pkg/project"
+	"github.com/gueckmooh/bs/pkg/watch"
+)
+
+// watchedFiles are the files whose changes make components be rebuilt:
+// the files of the directories of the components and the files their
+// objects were built from.
+type watchedFiles struct {
+	proj       *project.Project
+	components []*project.Component
+	inputs     map[*project.Component][]string
+}
+
+func newWatchedFiles(proj *project.Project, components []*project.Component) *watchedFiles {
+	return &watchedFiles{
+		proj:       proj,
+		components: components,
+		inputs:     make(map[*project.Component][]string),
+	}
+}
+
+// update records the files the components built by builder were built
+// from.
+func (w *watchedFiles) update(builder *build.ProjectBuilder) {
+	for _, c := range builder.GetComponents() {
+		w.inputs[c] = functional.ListMap(builder.GetInputFiles(c), func(file string) string {
+			return filepath.Join(w.proj.Config.ProjectRootDirectory, file)
+		})
+	}
+}
+
+func (w *watchedFiles) newPoller(opts ...watch.PollerOption) *watch.Poller {
+	files := []string{filepath.Join(w.proj.Config.ProjectRootDirectory, project.ProjectConfigFile)}
+	for _, c := range w.components {
+		files = append(files, w.inputs[c]...)
+	}
+	opts = append(opts,
+		watch.WithFiles(functional.ListUniq(files)...),
+		watch.WithDirectories(functional.ListMap(w.components,
+			func(c *project.Component) string { return c.Path })...),
+		watch.WithSkippedDirectories(w.proj.Config.GetBuildRootDirectory(false)))
+	return watch.NewPoller(opts...)
+}
+
+func isInDirectory(file, dir string) bool {
+	return strings.HasPrefix(file, dir+string(filepath.Separator))
+}
+
+// getAffectedComponents returns the components whose files changed, along
+// with the components depending on them, the dependencies of a component
+// coming before it.
+func (w *watchedFiles) getAffectedComponents(changes []string) []*project.Component {
+	changed := make(map[*project.Component]bool)
+	for _, c := range w.components {
+		changed[c] = functional.ListAnyOf(changes, func(file string) bool {
+			return isInDirectory(file, c.Path) || functional.ListIn(w.inputs[c], file)
+		})
+	}
+	return functional.ListFilter(w.components, func(c *project.Component) bool {
+		return changed[c] || functional.ListAnyOf(c.Dependencies,
+			func(d *project.Component) bool { return changed[d] })
+	})
+}
+
+func isConfigurationFile(file string) bool {
+	base := filepath.Base(file)
+	return base == project.ProjectConfigFile || base == project.ComponentConfigFile
+}
+
+func printWatchError(err error) {
+	fmt.Fprintf(os.Stderr, "%sError:%s %s\n", colors.ColorRed, colors.ColorReset, err.Error())
+}
+
+// watchProject builds the components ctbs, then waits for their files to
+// change and rebuilds the affected components until bs is interrupted.
+// The project is read again when its configuration files change, the lua
+// context and the project being kept in memory otherwise. The lua
+// context in use when it returns is closed.
+func watchProject(opts Options, C *lua.LuaContext, proj *project.Project, ctbs []string) error {
+	// C is replaced when the project is read again
+	defer func() { C.Close() }()
+	bops, err := getBuildOptions(opts, C, proj, *opts.buildOptions.buildUpstream)
+	if err != nil {
+		return err
+	}
+	builder, err := build.NewProjectBuilder(proj, ctbs, bops...)
+	if err != nil {
+		return err
+	}
+	ctx := opts.buildOptions.ctx
+	watched := newWatchedFiles(proj, builder.GetComponents())
+	var poller *watch.Poller
+	for {
+		if builder != nil {
+			// The files are recorded before the build, so that the
+			// changes made while it runs are not missed
+			base := watched.newPoller()
+			// The failures are reported by the builder
+			builder.Build()
+			watched.update(builder)
+			poller = watched.newPoller(watch.WithBaseline(base))
+			builder = nil
+		}
+
+		log.Info.Printf("%sWatching for changes, press Ctrl-C to stop...%s\n",
+			colors.ColorGray, colors.ColorReset)
+		changes := poller.Wait(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Log.Printf("%sInfo:%s changed files: %s\n", colors.ColorCyan, colors.ColorReset,
+			strings.Join(changes, ", "))
+
+		names := ctbs
+		buildUpstream := *opts.buildOptions.buildUpstream
+		if functional.ListAnyOf(changes, isConfigurationFile) {
+			log.Info.Printf("%sConfiguration changed, reading the project...%s\n",
+				colors.ColorGray, colors.ColorReset)
+			newC, newProj, _, err := openProject()
+			if err != nil {
+				// The files of the last project read are still
+				// watched, it is read again when they change
+				printWatchError(err)
+				continue
+			}
+			C.Close()
+			C, proj = newC, newProj
+		} else {
+			affected := watched.getAffectedComponents(changes)
+			if len(affected) == 0 {
+				continue
+			}
+			// The affected components are all the ones to rebuild
+			names = functional.ListMap(affected, func(c *project.Component) string { return c.Name })
+			buildUpstream = false
+		}
+
+		bops, err = getBuildOptions(opts, C, proj, buildUpstream)
+		if err == nil {
+			builder, err = build.NewProjectBuilder(proj, names, bops...)
+		}
+		if err != nil {
+			printWatchError(err)
+			continue
+		}
+		if watched.proj != proj {
+			watched = newWatchedFiles(proj, builder.GetComponents())
+		}
+	}
+}
@@ -17,14 +17,23 @@ type Bucket struct {
 }
 
 func NewBucket(nb int64) *Bucket {
+	return NewBucketWithContext(context.Background(), nb)
+}
+
+// NewBucketWithContext returns a bucket which refuses to run new
+// functions once ctx is done.
+func NewBucketWithContext(ctx context.Context, nb int64) *Bucket {
 	return &Bucket{
 		maxWorkers: nb,
 		sema:       semaphore.NewWeighted(nb),
-		ctx:        context.TODO(),
+		ctx:        ctx,
 	}
 }
 
 func (b *Bucket) Run(f func() error) error {
+	if err := b.ctx.Err(); err != nil {
+		return err
+	}
 	if err := b.sema.Acquire(b.ctx, 1); err != nil {
 		return err
 	}
@@ -41,6 +50,9 @@ func (b *Bucket) Run(f func() error) error {
 }
 
 func (b *Bucket) RunFailIfError(f func() error) error {
+	if err := b.ctx.Err(); err != nil {
+		return err
+	}
 	if err := b.sema.Acquire(b.ctx, 1); err != nil {
 		return err
 	}
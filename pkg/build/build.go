@@ -6,18 +6,22 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	alist "github.com/gueckmooh/bs/pkg/adjacency_list"
+	"github.com/gueckmooh/bs/pkg/bucket"
+	"github.com/gueckmooh/bs/pkg/builddb"
 	"github.com/gueckmooh/bs/pkg/ccpp"
-	"github.com/gueckmooh/bs/pkg/common/colors"
 	"github.com/gueckmooh/bs/pkg/compiler"
 	"github.com/gueckmooh/bs/pkg/fsutil"
 	"github.com/gueckmooh/bs/pkg/functional"
 	"github.com/gueckmooh/bs/pkg/globbing"
 	log "github.com/gueckmooh/bs/pkg/logging"
-	"github.com/gueckmooh/bs/pkg/lua"
+	"github.com/gueckmooh/bs/pkg/pkgconfig"
 	"github.com/gueckmooh/bs/pkg/project"
+	lua "github.com/yuin/gopher-lua"
 )
 
 type BuildKind int8
@@ -31,6 +35,7 @@ const (
 type FileDesc struct {
 	name             string
 	needsToBeRebuilt bool
+	rebuildReason    string
 	kind             int8
 }
 
@@ -43,45 +48,60 @@ func newFileDesc(name string, kind int8) *FileDesc {
 }
 
 type Builder struct {
+	buildConfig
 	Project          *project.Project
-	buildUpstream    bool
 	componentToBuild string
 	component        *project.Component
 	filesGraph       *alist.Graph[FileDesc, alist.AttributeNone]
 	targetVertex     alist.VertexDescriptor
 	filesVertices    map[string]alist.VertexDescriptor
-	alwaysBuild      bool
-	profile          string
-	platform         string
 	sourceFiles      []string
-	jobs             int
-	C                *lua.LuaContext
+	linkages         map[*project.Component]project.Linkage
+	linkedComponents []*project.Component
+	compiler         compiler.Compiler
+	db               *builddb.Database
+	headersExported  bool
 }
 
 func NewBuilder(p *project.Project, ctb string, opts ...BuildOption) (*Builder, error) {
+	config := defaultBuildConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	config.pkgConfig = newPkgConfig(p)
+	db, err := builddb.Open(p.Config.GetBuildDatabasePath(true))
+	if err != nil {
+		return nil, err
+	}
+	return newBuilderWithConfig(p, ctb, config, db)
+}
+
+func newBuilderWithConfig(p *project.Project, ctb string, config buildConfig,
+	db *builddb.Database,
+) (*Builder, error) {
 	component, err := p.GetComponent(ctb)
 	if err != nil {
 		return nil, err
 	}
 
 	builder := &Builder{
+		buildConfig:      config,
 		Project:          p,
-		buildUpstream:    false,
 		componentToBuild: ctb,
 		component:        component,
 		filesGraph:       alist.NewGraph[FileDesc, alist.AttributeNone](alist.DirectedGraph),
 		filesVertices:    make(map[string]alist.VertexDescriptor),
-		alwaysBuild:      false,
-		profile:          "Default",
-		jobs:             1,
-	}
-	for _, opt := range opts {
-		opt(builder)
+		db:               db,
 	}
 
 	return builder, nil
 }
 
+func (f *FileDesc) markForRebuild(reason string) {
+	f.needsToBeRebuilt = true
+	f.rebuildReason = reason
+}
+
 func (B *Builder) getOrCreateFileVertex(file string, kind int8) alist.VertexDescriptor {
 	if v, ok := B.filesVertices[file]; ok {
 		return v
@@ -97,35 +117,112 @@ func (B *Builder) getLinkOptionsForComponent() ([]compiler.CompilerOption, error
 	if len(B.component.Requires) > 0 {
 		opts = append(opts, compiler.WithLibraryDirectory(B.Project.Config.GetLibDirectory(true)))
 	}
-	var deps []*project.Component
-	if B.component.Type == project.TypeExecutable {
-		deps = B.component.Dependencies
-	} else {
-		deps = B.component.DirectDependencies
-	}
-	for _, dep := range deps {
-		if dep.Type != project.TypeHeaders {
+	for _, dep := range B.linkedComponents {
+		if B.linkages[dep] == project.LinkageStatic {
+			opts = append(opts, compiler.WithLibraryFile(dep.GetTargetName(project.LinkageStatic)))
+		} else {
 			opts = append(opts, compiler.WithLibrary(dep.Name))
-			profile, err := B.getProfileForComponent(dep)
-			if err != nil {
-				return nil, err
+		}
+	}
+	return opts, nil
+}
+
+// getRunPaths returns the run path of the target when it is in
+// targetDir and the shared libraries of the project are in libDir. Unless
+// it is set by the profile, it lets the target find the shared libraries
+// it is linked against relatively to its own location.
+func (B *Builder) getRunPaths(profile *project.Profile, targetDir, libDir string) ([]string, error) {
+	if B.component.Type == project.TypeLibrary && B.linkages[B.component] == project.LinkageStatic {
+		return nil, nil
+	}
+	switch profile.RPath {
+	case project.RPathNone:
+		return nil, nil
+	case "":
+		linksShared := functional.ListAnyOf(B.linkedComponents, func(dep *project.Component) bool {
+			return B.linkages[dep] == project.LinkageShared
+		})
+		if !linksShared {
+			return nil, nil
+		}
+		rel, err := filepath.Rel(targetDir, libDir)
+		if err != nil {
+			return nil, err
+		}
+		rpath := "$ORIGIN"
+		if rel != "." {
+			rpath = rpath + "/" + filepath.ToSlash(rel)
+		}
+		return []string{rpath}, nil
+	}
+	return strings.Split(profile.RPath, ":"), nil
+}
+
+// computeLinkages computes how the component and its dependencies are
+// linked with the current profile and platform.
+func (B *Builder) computeLinkages() error {
+	B.linkages = make(map[*project.Component]project.Linkage)
+	for _, c := range append([]*project.Component{B.component}, B.component.Dependencies...) {
+		profile, err := B.getProfileForComponent(c)
+		if err != nil {
+			return err
+		}
+		linkage := c.ResolveLinkage(profile.Linkage)
+		if linkage == project.LinkageUnknown {
+			return fmt.Errorf("Unknown linkage for component '%s'", c.Name)
+		}
+		B.linkages[c] = linkage
+	}
+	return nil
+}
+
+// computeLinkedComponents computes the components the target is linked
+// against, a component coming before the ones it depends on. Executables
+// are linked against all their dependencies, shared libraries against
+// their direct dependencies and the dependencies of the static libraries
+// they are linked against, and static libraries are not linked.
+func (B *Builder) computeLinkedComponents() {
+	linked := make(map[*project.Component]bool)
+	var addDependencies func(c *project.Component)
+	addDependencies = func(c *project.Component) {
+		for _, dep := range c.DirectDependencies {
+			if dep.Type == project.TypeHeaders || linked[dep] {
+				continue
 			}
-			for _, opt := range profile.GetCPPProfile().LinkOptions {
-				if strings.HasPrefix(opt, "-l") {
-					opts = append(opts, compiler.WithLibrary(strings.TrimPrefix(opt, "-l")))
-				} else if strings.HasPrefix(opt, "-L") {
-					opts = append(opts, compiler.WithLibraryDirectory(strings.TrimPrefix(opt, "-L")))
-				}
+			linked[dep] = true
+			if B.linkages[dep] == project.LinkageStatic {
+				addDependencies(dep)
 			}
 		}
 	}
-	if B.component.Type == project.TypeExecutable {
+	switch {
+	case B.component.IsExecutable():
+		for _, dep := range B.component.Dependencies {
+			linked[dep] = dep.Type != project.TypeHeaders
+		}
+	case B.linkages[B.component] == project.LinkageShared:
+		addDependencies(B.component)
+	}
+
+	B.linkedComponents = nil
+	deps := B.component.Dependencies
+	for i := len(deps) - 1; i >= 0; i-- {
+		if linked[deps[i]] {
+			B.linkedComponents = append(B.linkedComponents, deps[i])
+		}
 	}
-	return opts, nil
 }
 
-func (B *Builder) getIncludesOptionsForComponent() []compiler.CompilerOption {
+func (B *Builder) getIncludesOptionsForComponent(profile *project.Profile) []compiler.CompilerOption {
 	var opts []compiler.CompilerOption
+	dirs := append(append([]string{}, profile.GetCPPProfile().IncludeDirectories...),
+		profile.GetCProfile().IncludeDirectories...)
+	for _, dir := range functional.ListUniq(dirs) {
+		if rel, err := filepath.Rel(B.Project.Config.ProjectRootDirectory, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			dir = rel
+		}
+		opts = append(opts, compiler.WithIncludeDirectory(dir))
+	}
 	includeBase := B.Project.Config.GetExportedHeadersDirectory(true)
 	if B.component.Type == project.TypeLibrary {
 		opts = append(opts, compiler.WithIncludeDirectory(filepath.Join(includeBase, B.component.Name)))
@@ -138,49 +235,267 @@ func (B *Builder) getIncludesOptionsForComponent() []compiler.CompilerOption {
 	return opts
 }
 
+// getProfileLinkOptions returns the link options of all the languages
+// of profile.
+func getProfileLinkOptions(profile *project.Profile) []string {
+	var opts []string
+	opts = append(opts, profile.GetCPPProfile().LinkOptions...)
+	opts = append(opts, profile.GetCProfile().LinkOptions...)
+	return opts
+}
+
+// getProfilePublicLinkOptions returns the public link options of all the
+// languages of profile.
+func getProfilePublicLinkOptions(profile *project.Profile) []string {
+	var opts []string
+	opts = append(opts, profile.GetCPPProfile().PublicLinkOptions...)
+	opts = append(opts, profile.GetCProfile().PublicLinkOptions...)
+	return opts
+}
+
+// getDefineOptions returns the options defining the macros, sorted by
+// name so that the commands do not change from a build to another.
+func getDefineOptions(defines project.Defines) []string {
+	return functional.ListMap(defines.Names(), func(name string) string {
+		if value := defines[name]; value != nil {
+			return "-D" + name + "=" + *value
+		}
+		return "-D" + name
+	})
+}
+
+// getProfilePublicCompileOptions returns the public build options and
+// defines of the C++ and C languages of profile.
+func getProfilePublicCompileOptions(profile *project.Profile) (cppOpts, cOpts []string) {
+	cpp, c := profile.GetCPPProfile(), profile.GetCProfile()
+	cppOpts = append(append(cppOpts, cpp.PublicBuildOptions...), getDefineOptions(cpp.PublicDefines)...)
+	cOpts = append(append(cOpts, c.PublicBuildOptions...), getDefineOptions(c.PublicDefines)...)
+	return cppOpts, cOpts
+}
+
+// getPublicOptions returns the compiler options of the public build
+// options, defines and link options of profile.
+func getPublicOptions(profile *project.Profile) []compiler.CompilerOption {
+	var opts []compiler.CompilerOption
+	cppOpts, cOpts := getProfilePublicCompileOptions(profile)
+	for _, v := range cppOpts {
+		opts = append(opts, compiler.WithBuildOption(v))
+	}
+	for _, v := range cOpts {
+		opts = append(opts, compiler.WithCBuildOption(v))
+	}
+	for _, v := range getProfilePublicLinkOptions(profile) {
+		opts = append(opts, compiler.WithLinkOption(v))
+	}
+	return opts
+}
+
+// getDependenciesPublicOptions returns the public options of all the
+// components the component depends on, directly or not.
+func (B *Builder) getDependenciesPublicOptions() ([]compiler.CompilerOption, error) {
+	var opts []compiler.CompilerOption
+	for _, dep := range B.component.Dependencies {
+		profile, err := B.getProfileForComponent(dep)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, getPublicOptions(profile)...)
+	}
+	return opts, nil
+}
+
+// getPackages returns the packages required by the components.
+func (B *Builder) getPackages(components ...*project.Component) ([]*pkgconfig.Package, error) {
+	var packages []*pkgconfig.Package
+	for _, c := range components {
+		for _, spec := range c.Packages {
+			pkg, err := B.pkgConfig.Query(spec)
+			if err != nil {
+				return nil, fmt.Errorf("Component '%s' requires a missing package:\n\t%s", c.Name, err.Error())
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	return functional.ListUniq(packages), nil
+}
+
+// getPackageOptions returns the options needed to use the packages
+// required by the component. The sources are compiled with the flags of
+// the packages required by the component and its dependencies, and the
+// target is linked against the ones of the components it is linked with.
+func (B *Builder) getPackageOptions() ([]compiler.CompilerOption, error) {
+	var opts []compiler.CompilerOption
+	packages, err := B.getPackages(append([]*project.Component{B.component}, B.component.Dependencies...)...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range packages {
+		for _, flag := range pkg.CFlags {
+			opts = append(opts, compiler.WithBuildOption(flag), compiler.WithCBuildOption(flag))
+		}
+	}
+
+	linked := B.linkedComponents
+	if !(B.component.Type == project.TypeLibrary && B.linkages[B.component] == project.LinkageStatic) {
+		linked = append([]*project.Component{B.component}, linked...)
+	}
+	packages, err = B.getPackages(linked...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range packages {
+		for _, flag := range pkg.Libs {
+			opts = append(opts, compiler.WithLinkOption(flag))
+		}
+	}
+	return opts, nil
+}
+
+// getToolchainOptions returns the options selecting the toolchain of
+// profile. The compilers given by the CC and CXX environment variables
+// take precedence over the ones of the toolchain, they must belong to the
+// same toolchain as the flags of the toolchain are used for both.
+func getToolchainOptions(profile *project.Profile) ([]compiler.CompilerOption, error) {
+	var opts []compiler.CompilerOption
+	cc, cxx := os.Getenv("CC"), os.Getenv("CXX")
+	var toolchains, prefixes []string
+	for _, exe := range []string{cc, cxx} {
+		if t := compiler.ToolchainFromExecutable(exe); exe != "" && t != "" {
+			toolchains = append(toolchains, t)
+		}
+		if p := compiler.ToolchainPrefixFromExecutable(exe); exe != "" && p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	if len(functional.ListUniq(toolchains)) > 1 || len(functional.ListUniq(prefixes)) > 1 {
+		return nil, fmt.Errorf("The compilers CC='%s' and CXX='%s' belong to different toolchains", cc, cxx)
+	}
+	toolchain, prefix := profile.Toolchain, profile.ToolchainPrefix
+	if len(toolchains) > 0 {
+		toolchain = toolchains[0]
+	}
+	if len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+	if toolchain != "" {
+		opts = append(opts, compiler.WithToolchain(toolchain))
+	}
+	if prefix != "" {
+		opts = append(opts, compiler.WithToolchainPrefix(prefix))
+	}
+	if cc != "" {
+		opts = append(opts, compiler.WithCCompiler(cc))
+	}
+	if cxx != "" {
+		opts = append(opts, compiler.WithCXXCompiler(cxx))
+	}
+	return opts, nil
+}
+
+// usesLanguage tells if lang is enabled for the component, the languages
+// of the project are used when the component does not set any.
+func (B *Builder) usesLanguage(lang project.LanguageID) bool {
+	langs := B.component.Languages
+	if len(langs) == 0 {
+		langs = B.Project.Languages
+	}
+	return functional.ListIn(langs, lang)
+}
+
+// linksWithCPP tells if the target needs to be linked with the C++
+// driver, which is the case as soon as one of its sources is C++.
+func (B *Builder) linksWithCPP() bool {
+	for _, file := range B.sourceFiles {
+		if !ccpp.IsCSourceFile(file) {
+			return true
+		}
+	}
+	return false
+}
+
 func (B *Builder) getProfileForComponent(c *project.Component) (*project.Profile, error) {
 	projectProfile, err := B.Project.ComputeProfile(B.profile)
 	if err != nil {
 		return nil, err
 	}
-	componentProfile := B.component.ComputeProfile(B.profile)
+	componentProfile := c.ComputeProfile(B.profile)
 
 	projectPlatform, err := B.Project.ComputePlatform(B.platform)
 	if err != nil {
 		return nil, err
 	}
-	componentPlatform := B.component.ComputePlatform(B.platform)
+	componentPlatform := c.ComputePlatform(B.platform)
 	platform := projectPlatform.Merge(componentPlatform)
 
 	profile := projectProfile.Merge(componentProfile).Merge(platform)
 	return profile, err
 }
 
-func (B *Builder) getCompilerOptionsForComponent() ([]compiler.CompilerOption, error) {
+// getCompilerOptionsForComponent returns the options of the compiler
+// building the component, its target being in targetDir and the shared
+// libraries of the project in libDir.
+func (B *Builder) getCompilerOptionsForComponent(targetDir, libDir string) ([]compiler.CompilerOption, error) {
 	var opts []compiler.CompilerOption
-	{
-		o := B.getIncludesOptionsForComponent()
-		opts = append(opts, o...)
+	profile, err := B.getProfileForComponent(B.component)
+	if err != nil {
+		return nil, err
 	}
+
+	opts = append(opts, B.getIncludesOptionsForComponent(profile)...)
 	if o, err := B.getLinkOptionsForComponent(); err != nil {
 		return nil, err
 	} else {
 		opts = append(opts, o...)
 	}
 
-	profile, err := B.getProfileForComponent(B.component)
-	if err != nil {
-		return nil, err
-	}
-
 	opts = append(opts, compiler.WithCPPDIalect(profile.GetCPPProfile().Dialect))
+	opts = append(opts,
+		compiler.WithOptimization(profile.GetCPPProfile().Optimize),
+		compiler.WithSymbols(profile.GetCPPProfile().Symbols),
+		compiler.WithLTO(profile.GetCPPProfile().LTO),
+		compiler.WithSanitizers(profile.GetCPPProfile().Sanitizers...))
 	for _, v := range profile.GetCPPProfile().BuildOptions {
 		opts = append(opts, compiler.WithBuildOption(v))
 	}
-	for _, v := range profile.GetCPPProfile().LinkOptions {
+	for _, v := range getDefineOptions(profile.GetCPPProfile().Defines) {
+		opts = append(opts, compiler.WithBuildOption(v))
+	}
+	opts = append(opts, compiler.WithCDialect(profile.GetCProfile().Dialect))
+	for _, v := range profile.GetCProfile().BuildOptions {
+		opts = append(opts, compiler.WithCBuildOption(v))
+	}
+	for _, v := range getDefineOptions(profile.GetCProfile().Defines) {
+		opts = append(opts, compiler.WithCBuildOption(v))
+	}
+	for _, v := range getProfileLinkOptions(profile) {
 		opts = append(opts, compiler.WithLinkOption(v))
 	}
-	return opts, nil
+	opts = append(opts, getPublicOptions(profile)...)
+	if o, err := B.getDependenciesPublicOptions(); err != nil {
+		return nil, err
+	} else {
+		opts = append(opts, o...)
+	}
+	if o, err := B.getPackageOptions(); err != nil {
+		return nil, err
+	} else {
+		opts = append(opts, o...)
+	}
+	if rpaths, err := B.getRunPaths(profile, targetDir, libDir); err != nil {
+		return nil, err
+	} else {
+		for _, v := range rpaths {
+			opts = append(opts, compiler.WithRunPath(v))
+		}
+	}
+	if B.linksWithCPP() {
+		opts = append(opts, compiler.ForCPP)
+	}
+	toolchainOpts, err := getToolchainOptions(profile)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, toolchainOpts...), nil
 }
 
 func (B *Builder) isBuildableNode(v alist.VertexDescriptor) bool {
@@ -191,44 +506,116 @@ func (B *Builder) isBuildableNode(v alist.VertexDescriptor) bool {
 	return false
 }
 
+// getLinkedTargets returns the targets of the components the component
+// is linked against.
+func (B *Builder) getLinkedTargets() []string {
+	return functional.ListMap(B.linkedComponents, func(dep *project.Component) string {
+		return filepath.Join(B.Project.Config.GetLibDirectory(true), dep.GetTargetName(B.linkages[dep]))
+	})
+}
+
+// getNodeInputs returns the files the content of the buildable node v
+// depends on.
+func (B *Builder) getNodeInputs(v alist.VertexDescriptor) ([]string, error) {
+	neighbors, err := B.filesGraph.Neighbors(v)
+	if err != nil {
+		return nil, err
+	}
+	inputs := functional.ListMap(neighbors, func(n alist.VertexDescriptor) string {
+		return B.filesGraph.GetVertexAttribute(n).name
+	})
+	if v == B.targetVertex {
+		inputs = append(inputs, B.getLinkedTargets()...)
+	}
+	return functional.ListUniq(inputs), nil
+}
+
+// getNodeCommand returns the command used to build the buildable node v.
+func (B *Builder) getNodeCommand(v alist.VertexDescriptor) ([]string, error) {
+	attr := B.filesGraph.GetVertexAttribute(v)
+	if v == B.targetVertex {
+		neighbors, err := B.filesGraph.Neighbors(v)
+		if err != nil {
+			return nil, err
+		}
+		objects := functional.ListMap(neighbors, func(n alist.VertexDescriptor) string {
+			return B.filesGraph.GetVertexAttribute(n).name
+		})
+		return B.compiler.LinkCommand(attr.name, objects...), nil
+	}
+	source, err := B.getSourceToCompile(v)
+	if err != nil {
+		return nil, err
+	}
+	return B.compiler.CompileCommand(attr.name, B.filesGraph.GetVertexAttribute(source).name), nil
+}
+
+// recordNode records how the buildable node v has been built from
+// inputs in the build database.
+func (B *Builder) recordNode(v alist.VertexDescriptor, inputs []string) error {
+	command, err := B.getNodeCommand(v)
+	if err != nil {
+		return err
+	}
+	return B.db.SetRecord(B.filesGraph.GetVertexAttribute(v).name, command, inputs)
+}
+
 func (B *Builder) computeWhatNeedsToBeRebuilt() (bool, error) {
+	visited := make(map[alist.VertexDescriptor]bool)
 	var checkNode func(alist.VertexDescriptor) error
 	checkNode = func(v alist.VertexDescriptor) error {
+		if visited[v] {
+			return nil
+		}
+		visited[v] = true
 		oe, err := B.filesGraph.OutEdges(v)
 		if err != nil {
 			return err
 		}
 		for _, ed := range oe {
 			target, _ := B.filesGraph.Target(ed)
-			checkNode(target)
+			if err := checkNode(target); err != nil {
+				return err
+			}
 		}
 		if B.filesGraph.IsLeef(v) {
 			return nil
 		}
 
+		attr := B.filesGraph.GetVertexAttribute(v)
 		if B.alwaysBuild && B.isBuildableNode(v) {
-			B.filesGraph.GetVertexAttribute(v).needsToBeRebuilt = true
+			attr.markForRebuild("always build")
 			return nil
 		}
 
-		stat, err := os.Stat(B.filesGraph.GetVertexAttribute(v).name)
-		if os.IsNotExist(err) {
-			B.filesGraph.GetVertexAttribute(v).needsToBeRebuilt = true
+		if _, err := os.Stat(attr.name); os.IsNotExist(err) {
+			attr.markForRebuild("does not exist")
 			return nil
 		}
 
 		for _, ed := range oe {
 			target, _ := B.filesGraph.Target(ed)
 			if B.filesGraph.GetVertexAttribute(target).needsToBeRebuilt {
-				B.filesGraph.GetVertexAttribute(v).needsToBeRebuilt = true
-				return nil
-			}
-			statTarget, _ := os.Stat(B.filesGraph.GetVertexAttribute(target).name) // @todo handle error
-			if stat.ModTime().Before(statTarget.ModTime()) {
-				B.filesGraph.GetVertexAttribute(v).needsToBeRebuilt = true
+				attr.markForRebuild(fmt.Sprintf("%s is rebuilt", B.filesGraph.GetVertexAttribute(target).name))
 				return nil
 			}
 		}
+
+		command, err := B.getNodeCommand(v)
+		if err != nil {
+			return err
+		}
+		inputs, err := B.getNodeInputs(v)
+		if err != nil {
+			return err
+		}
+		reason, err := B.db.OutdatedReason(attr.name, command, inputs)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			attr.markForRebuild(reason)
+		}
 		return nil
 	}
 	err := checkNode(B.targetVertex)
@@ -238,7 +625,31 @@ func (B *Builder) computeWhatNeedsToBeRebuilt() (bool, error) {
 	return B.filesGraph.GetVertexAttribute(B.targetVertex).needsToBeRebuilt, nil
 }
 
-func (B *Builder) computeFilesDependencies() error {
+// explainRebuilds prints why each file of the component needs to be
+// rebuilt.
+func (B *Builder) explainRebuilds() {
+	visited := make(map[alist.VertexDescriptor]bool)
+	var explainNode func(alist.VertexDescriptor)
+	explainNode = func(v alist.VertexDescriptor) {
+		if visited[v] {
+			return
+		}
+		visited[v] = true
+		neighbors, _ := B.filesGraph.Neighbors(v)
+		for _, n := range neighbors {
+			explainNode(n)
+		}
+		attr := B.filesGraph.GetVertexAttribute(v)
+		if attr.needsToBeRebuilt {
+			B.emit(&Event{Kind: EventRebuildExplained, File: attr.name, Message: attr.rebuildReason})
+		}
+	}
+	explainNode(B.targetVertex)
+}
+
+// computeSourceFiles lists the sources of the component, C sources are
+// only built when the C language is enabled.
+func (B *Builder) computeSourceFiles() error {
 	sourceMatchers := functional.ListMap(B.component.GetSourcesForProfileAndPlatform(B.profile, B.platform),
 		func(s project.FilesPattern) *globbing.Pattern {
 			return globbing.NewPattern(string(s))
@@ -248,70 +659,101 @@ func (B *Builder) computeFilesDependencies() error {
 	if err != nil {
 		return err
 	}
-	sourceFiles = ccpp.FilterCPPSourceFiles(sourceFiles)
+	withC := B.usesLanguage(project.LangC)
+	sourceFiles = functional.ListFilter(sourceFiles, func(s string) bool {
+		return ccpp.IsCPPSourceFile(s) || (withC && ccpp.IsCSourceFile(s))
+	})
 	sourceFiles, err = fsutil.RelAll(B.Project.Config.ProjectRootDirectory, sourceFiles)
 	if err != nil {
 		return err
 	}
 
 	B.sourceFiles = sourceFiles
+	return nil
+}
 
-	var targetDir string
-	switch B.component.Type {
-	case project.TypeExecutable:
-		targetDir = B.Project.Config.GetBinDirectory(true)
-	case project.TypeLibrary:
-		targetDir = B.Project.Config.GetLibDirectory(true)
-	}
-
-	targetPath := filepath.Join(targetDir, B.component.GetTargetName())
+func (B *Builder) computeFilesDependencies() error {
+	targetPath := filepath.Join(B.Project.Config.GetTargetDirectory(B.component.Type, true),
+		B.component.GetTargetName(B.linkages[B.component]))
 	targetVertex := B.filesGraph.AddVertex(newFileDesc(targetPath, fileLinkedKind))
 	B.targetVertex = targetVertex
 
-	for _, file := range sourceFiles {
-		err := B.computeFileDependency(file)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return B.computeObjectsDependencies(B.sourceFiles)
 }
 
-func (B *Builder) computeFileDependency(sourceFile string) error {
+func (B *Builder) getObjectFile(sourceFile string) (string, error) {
 	fileWithoutSuffix := strings.TrimSuffix(sourceFile, filepath.Ext(sourceFile))
 	fileWithoutSuffix, err := filepath.Abs(fileWithoutSuffix)
 	if err != nil {
-		return err
+		return "", err
 	}
 	fileWithoutSuffix, err = filepath.Rel(B.component.Path, fileWithoutSuffix)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	targetFile := filepath.Join(B.Project.Config.GetObjDirectory(true), B.component.Name,
-		fileWithoutSuffix+".o")
+	return filepath.Join(B.Project.Config.GetObjDirectory(true), B.component.Name,
+		fileWithoutSuffix+".o"), nil
+}
 
-	compilerOpts, err := B.getCompilerOptionsForComponent()
-	if err != nil {
+// getObjectDependencies returns the files the object file target is
+// built from. They are read from the dependency file written when the
+// object was last compiled, and only scanned when the object exists
+// without it. An object that does not exist only depends on its source
+// as it is compiled anyway.
+func (B *Builder) getObjectDependencies(target, source string) ([]string, error) {
+	if _, err := os.Stat(target); os.IsNotExist(err) {
+		return []string{source}, nil
+	}
+	sources, err := B.compiler.ReadFileDependencies(target)
+	if err == nil {
+		return sources, nil
+	}
+	log.Debug.Printf("Could not read dependencies of %s, scanning them: %s\n", target, err.Error())
+	sources, output, err := B.compiler.GetFileDependencies(B.ctx, target, source)
+	B.emitDiagnostics(source, output)
+	return sources, err
+}
+
+// computeObjectsDependencies adds the object files of sourceFiles to
+// the files graph along with the files they are built from, the
+// dependencies of the objects are computed in parallel.
+func (B *Builder) computeObjectsDependencies(sourceFiles []string) error {
+	targets := make([]string, len(sourceFiles))
+	dependencies := make([][]string, len(sourceFiles))
+	jobs := B.jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	b := bucket.NewBucketWithContext(B.ctx, int64(jobs))
+	for i, file := range sourceFiles {
+		i, file := i, file
+		target, err := B.getObjectFile(file)
+		if err != nil {
+			return err
+		}
+		targets[i] = target
+		err = b.Run(func() error {
+			deps, err := B.getObjectDependencies(target, file)
+			dependencies[i] = deps
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := b.Wait(); err != nil {
 		return err
 	}
-	compiler := compiler.NewCompiler(compilerOpts...)
-	target, sources, err := compiler.GetFileDependencies(targetFile, sourceFile)
-	if err != nil {
+	if err := b.Error(); err != nil {
 		return err
 	}
 
-	targetVertex := B.getOrCreateFileVertex(target, fileObjectKind)
-
-	B.filesGraph.AddEdge(B.targetVertex, targetVertex)
-
-	for _, file := range sources {
-		fileV := B.getOrCreateFileVertex(file, fileSourceKind)
-		if ccpp.IsCPPSourceFile(file) {
-			B.filesGraph.AddEdge(targetVertex, fileV)
-		} else {
-			B.filesGraph.AddEdge(targetVertex, fileV)
+	for i, target := range targets {
+		targetVertex := B.getOrCreateFileVertex(target, fileObjectKind)
+		B.filesGraph.AddEdge(B.targetVertex, targetVertex)
+		for _, file := range dependencies[i] {
+			B.filesGraph.AddEdge(targetVertex, B.getOrCreateFileVertex(file, fileSourceKind))
 		}
 	}
 	return nil
@@ -327,7 +769,7 @@ func (B *Builder) getSourceToCompile(v alist.VertexDescriptor) (alist.VertexDesc
 		if err != nil {
 			return 0, err
 		}
-		if ccpp.IsCPPSourceFile(B.filesGraph.GetVertexAttribute(source).name) {
+		if ccpp.IsSourceFile(B.filesGraph.GetVertexAttribute(source).name) {
 			return source, nil
 		}
 	}
@@ -335,13 +777,23 @@ func (B *Builder) getSourceToCompile(v alist.VertexDescriptor) (alist.VertexDesc
 		B.filesGraph.GetVertexAttribute(v).name)
 }
 
-func (B *Builder) PreBuild() error {
-	if len(B.component.PrebuildActions) > 0 {
-		fmt.Printf("%sRunning prebuild hooks...%s\n",
-			colors.ColorGray, colors.ColorReset)
+// runHooks runs the hooks of the given stage, in the order they were
+// declared.
+func (B *Builder) runHooks(stage string, hooks []*lua.LFunction) error {
+	if len(hooks) > 0 {
+		B.emit(&Event{Kind: EventHooksStarted, Stage: stage})
 	}
-	for _, pb := range B.component.PrebuildActions {
-		err := B.RunLuaFunction(pb)
+	for _, hook := range hooks {
+		start := time.Now()
+		err := B.RunLuaFunction(hook)
+		e := &Event{Kind: EventHookRun, Stage: stage,
+			File:     fmt.Sprintf("%s:%d", hook.Proto.SourceName, hook.Proto.LineDefined),
+			Duration: getDuration(start), Status: StatusSucceeded}
+		if err != nil {
+			e.Status = StatusFailed
+			e.Message = err.Error()
+		}
+		B.emit(e)
 		if err != nil {
 			return err
 		}
@@ -349,92 +801,161 @@ func (B *Builder) PreBuild() error {
 	return nil
 }
 
+func (B *Builder) PreBuild() error {
+	return B.runHooks(StagePrebuild, B.component.PrebuildActions)
+}
+
 func (B *Builder) PostBuild() error {
-	if len(B.component.PostbuildActions) > 0 {
-		fmt.Printf("%sRunning postbuild hooks...%s\n",
-			colors.ColorGray, colors.ColorReset)
+	return B.runHooks(StagePostbuild, B.component.PostbuildActions)
+}
+
+func (B *Builder) newCompiler() (compiler.Compiler, error) {
+	return B.newCompilerForLayout(B.Project.Config.GetTargetDirectory(B.component.Type, false),
+		B.Project.Config.GetLibDirectory(false))
+}
+
+// newCompilerForLayout returns the compiler building the component, its
+// target being in targetDir and the shared libraries of the project in
+// libDir, which is where they are built unless they are installed.
+func (B *Builder) newCompilerForLayout(targetDir, libDir string) (compiler.Compiler, error) {
+	compilerOptions, err := B.getCompilerOptionsForComponent(targetDir, libDir)
+	if err != nil {
+		return nil, err
 	}
-	for _, pb := range B.component.PostbuildActions {
-		err := B.RunLuaFunction(pb)
-		if err != nil {
-			return err
-		}
+	switch {
+	case B.component.Type == project.TypeLibrary && B.linkages[B.component] == project.LinkageStatic:
+		compilerOptions = append(compilerOptions, compiler.TargetStaticLib)
+	case B.component.Type == project.TypeLibrary:
+		compilerOptions = append(compilerOptions, compiler.TargetLib)
 	}
-	return nil
+	return compiler.NewCompiler(compilerOptions...)
 }
 
-func (B *Builder) Build() error {
-	fmt.Printf("%sBuilding target...%s\n",
-		colors.ColorGray, colors.ColorReset)
+// compileObject compiles the object file of vertex v from its source.
+func (B *Builder) compileObject(v alist.VertexDescriptor) error {
 	g := B.filesGraph
-	var comp compiler.Compiler
-	compilerOptions, err := B.getCompilerOptionsForComponent()
+	err := fsutil.MkdirRecIfNotExist(filepath.Dir(g.GetVertexAttribute(v).name))
 	if err != nil {
 		return err
 	}
-	switch B.component.Type {
-	case project.TypeLibrary:
-		compilerOptions = append(compilerOptions, compiler.TargetLib)
+	source, err := B.getSourceToCompile(v)
+	if err != nil {
+		return err
+	}
+	target, file := g.GetVertexAttribute(v).name, g.GetVertexAttribute(source).name
+	B.emit(&Event{Kind: EventCompileStarted, File: file, Target: target})
+	start := time.Now()
+	output, err := B.compiler.CompileFile(B.ctx, target, file)
+	B.emitDiagnostics(file, output)
+	B.emit(&Event{Kind: EventFileCompiled, File: file, Target: target,
+		Duration: getDuration(start), ExitStatus: getExitStatus(err)})
+	if err != nil {
+		B.db.RemoveRecord(g.GetVertexAttribute(v).name)
+		return err
+	}
+	// The dependencies of the object may have changed since the files
+	// graph has been computed
+	inputs, err := B.compiler.ReadFileDependencies(g.GetVertexAttribute(v).name)
+	if err != nil {
+		return err
 	}
-	comp = compiler.NewCompiler(compilerOptions...)
-	scheduler := compiler.NewScheduler(comp, int64(B.jobs))
+	return B.recordNode(v, inputs)
+}
 
-	var buildNode func(alist.VertexDescriptor) error
-	buildNode = func(v alist.VertexDescriptor) error {
-		oe, err := g.OutEdges(v)
+// getTargetSources returns the object files the target is linked from.
+func (B *Builder) getTargetSources() ([]string, error) {
+	g := B.filesGraph
+	oe, err := g.OutEdges(B.targetVertex)
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	for _, ed := range oe {
+		source, err := g.Target(ed)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		sources = append(sources, g.GetVertexAttribute(source).name)
+	}
+	return sources, nil
+}
 
-		for _, ed := range oe {
-			target, err := g.Target(ed)
-			if err != nil {
-				return err
-			}
-			err = buildNode(target)
-			if err != nil {
-				return err
-			}
-		}
-		if !g.GetVertexAttribute(v).needsToBeRebuilt {
-			return nil
-		}
+// linkFiles links the object files sources into target with comp.
+func (B *Builder) linkFiles(comp compiler.Compiler, target string, sources []string) error {
+	archive := B.component.Type == project.TypeLibrary && B.linkages[B.component] == project.LinkageStatic
+	B.emit(&Event{Kind: EventLinkStarted, Target: target, Archive: archive})
+	start := time.Now()
+	output, err := comp.LinkFiles(B.ctx, target, sources...)
+	B.emitDiagnostics(target, output)
+	B.emit(&Event{Kind: EventTargetLinked, Target: target, Archive: archive,
+		Duration: getDuration(start), ExitStatus: getExitStatus(err)})
+	return err
+}
+
+// linkTarget links the component target from all its object files.
+func (B *Builder) linkTarget() error {
+	g := B.filesGraph
+	err := fsutil.MkdirRecIfNotExist(filepath.Dir(g.GetVertexAttribute(B.targetVertex).name))
+	if err != nil {
+		return err
+	}
+	sources, err := B.getTargetSources()
+	if err != nil {
+		return err
+	}
+	err = B.linkFiles(B.compiler, g.GetVertexAttribute(B.targetVertex).name, sources)
+	if err != nil {
+		B.db.RemoveRecord(g.GetVertexAttribute(B.targetVertex).name)
+		return err
+	}
+	inputs, err := B.getNodeInputs(B.targetVertex)
+	if err != nil {
+		return err
+	}
+	return B.recordNode(B.targetVertex, inputs)
+}
 
-		// Make sure the directory exists
-		dir := filepath.Dir(g.GetVertexAttribute(v).name)
-		err = fsutil.MkdirRecIfNotExist(dir)
+// getObjectsToCompile returns the vertices of the object files that
+// need to be rebuilt.
+func (B *Builder) getObjectsToCompile() ([]alist.VertexDescriptor, error) {
+	var objects []alist.VertexDescriptor
+	oe, err := B.filesGraph.OutEdges(B.targetVertex)
+	if err != nil {
+		return nil, err
+	}
+	for _, ed := range oe {
+		v, err := B.filesGraph.Target(ed)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		attr := B.filesGraph.GetVertexAttribute(v)
+		if attr.kind == fileObjectKind && attr.needsToBeRebuilt && !B.filesGraph.IsLeef(v) {
+			objects = append(objects, v)
 		}
+	}
+	return objects, nil
+}
 
-		// Compile object files
-		if len(oe) > 0 && g.GetVertexAttribute(v).kind == fileObjectKind {
-			source, err := B.getSourceToCompile(v)
-			if err != nil {
-				return err
-			}
-			err = scheduler.CompileFile(g.GetVertexAttribute(v).name, g.GetVertexAttribute(source).name)
-			if err != nil {
-				return err
-			}
-			// Link linkable file
-		} else if len(oe) > 0 && g.GetVertexAttribute(v).kind == fileLinkedKind {
-			var sources []string
-			for _, ed := range oe {
-				source, err := g.Target(ed)
-				if err != nil {
-					return nil
-				}
-				sources = append(sources, g.GetVertexAttribute(source).name)
-			}
-			err = scheduler.LinkFiles(g.GetVertexAttribute(v).name, sources...)
-			if err != nil {
-				return err
-			}
+func (B *Builder) needsToBeRebuilt() bool {
+	return B.filesGraph.GetVertexAttribute(B.targetVertex).needsToBeRebuilt
+}
+
+func (B *Builder) hasTarget() bool {
+	return B.component.Type != project.TypeHeaders && !B.filesGraph.IsLeef(B.targetVertex)
+}
+
+// getInputFiles returns the files the objects of the component are built
+// from, it is empty until the component is prepared.
+func (B *Builder) getInputFiles() []string {
+	var files []string
+	for _, v := range B.filesGraph.GetVertices() {
+		attr := B.filesGraph.GetVertexAttribute(v)
+		if attr.kind == fileSourceKind {
+			files = append(files, attr.name)
 		}
-		return nil
 	}
-	return buildNode(B.targetVertex)
+	sort.Strings(files)
+	return files
 }
 
 func (B *Builder) DumpComponentToBuild() string {
@@ -448,26 +969,54 @@ func (B *Builder) DumpComponentToBuild() string {
 	return buff.String()
 }
 
-func (B *Builder) tryBuildComponent() (bool, error) {
+// prepareCompiler lists the sources of the component and creates the
+// compiler used to build them.
+func (B *Builder) prepareCompiler() error {
+	if err := B.computeSourceFiles(); err != nil {
+		return err
+	}
+
+	if err := B.computeLinkages(); err != nil {
+		return err
+	}
+	B.computeLinkedComponents()
+
+	var err error
+	B.compiler, err = B.newCompiler()
+	return err
+}
+
+// Prepare runs the prebuild hooks, exports the headers and computes the
+// files of the component that need to be rebuilt. It must be called
+// before the component is scheduled in a build graph.
+func (B *Builder) Prepare() error {
+	if B.component.Type == project.TypeUnknown {
+		return fmt.Errorf("Unable to build component with unknown type %s", B.componentToBuild)
+	}
+
 	err := B.PreBuild()
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	headersExported, err := B.exportHeaders()
+	B.headersExported, err = B.exportHeaders()
 	if err != nil {
-		return false, err
+		return err
+	}
+
+	if err := B.prepareCompiler(); err != nil {
+		return err
 	}
 
 	if err := B.computeFilesDependencies(); err != nil {
-		return false, err
+		return err
 	}
 
 	log.Debug.Write(B.DumpComponentToBuild())
 
-	needBuild, err := B.computeWhatNeedsToBeRebuilt()
+	_, err = B.computeWhatNeedsToBeRebuilt()
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	vertexWritterOption := alist.WithVertexLabelWritter[FileDesc, alist.AttributeNone](
@@ -480,36 +1029,5 @@ func (B *Builder) tryBuildComponent() (bool, error) {
 		})
 	ioutil.WriteFile("/tmp/graphviz.dot", []byte(B.filesGraph.DumpGraphviz(vertexWritterOption)), 0o600)
 
-	if needBuild {
-		err = B.Build()
-		if err != nil {
-			return false, err
-		}
-		err = B.PostBuild()
-		if err != nil {
-			return false, err
-		}
-	}
-
-	return headersExported || needBuild, nil
-}
-
-func (B *Builder) BuildComponent() error {
-	if B.component.Type == project.TypeUnknown {
-		return fmt.Errorf("Unable to build component with unknown type %s", B.componentToBuild)
-	}
-
-	fmt.Printf("--------------- Building component '%s'...\n", B.componentToBuild)
-	done, err := B.tryBuildComponent()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error while building componnent '%s':\n\t%s\n", B.componentToBuild, err.Error())
-		fmt.Printf("--------------- Failed to build component '%s'\n", B.componentToBuild)
-		return fmt.Errorf("Build of component '%s' failed", B.componentToBuild)
-	}
-	if done {
-		fmt.Printf("--------------- Build successful\n")
-	} else {
-		fmt.Printf("--------------- Nothing to be done for '%s'\n", B.componentToBuild)
-	}
 	return nil
 }
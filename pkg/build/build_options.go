@@ -1,37 +1,124 @@
 package build
 
-import "github.com/gueckmooh/bs/pkg/lua"
+import (
+	"context"
+	"path/filepath"
 
-type BuildOption func(b *Builder)
+	"github.com/gueckmooh/bs/pkg/lua"
+	"github.com/gueckmooh/bs/pkg/pkgconfig"
+	"github.com/gueckmooh/bs/pkg/project"
+)
 
-// func WithBuildUpstream(b *Builder) {
-// 	b.buildUpstream = true
-// }
+type buildConfig struct {
+	ctx           context.Context
+	buildUpstream bool
+	alwaysBuild   bool
+	explain       bool
+	keepGoing     bool
+	compdb        bool
+	profile       string
+	platform      string
+	jobs          int
+	C             *lua.LuaContext
+	pkgConfig     *pkgconfig.PkgConfig
+	events        *eventEmitter
+	sarifReport   string
+}
+
+func defaultBuildConfig() buildConfig {
+	return buildConfig{
+		ctx:           context.Background(),
+		buildUpstream: false,
+		alwaysBuild:   false,
+		profile:       "Default",
+		jobs:          1,
+		events:        newEventEmitter(NewConsoleEventHandler()),
+	}
+}
+
+// newPkgConfig returns the pkg-config used to find the packages required
+// by the components of the project.
+func newPkgConfig(p *project.Project) *pkgconfig.PkgConfig {
+	var paths []string
+	for _, path := range p.PkgConfigPath {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(p.Config.ProjectRootDirectory, path)
+		}
+		paths = append(paths, path)
+	}
+	return pkgconfig.NewPkgConfig(pkgconfig.WithSearchPath(paths...))
+}
 
-func WithAlwaysBuild(b *Builder) {
+type BuildOption func(b *buildConfig)
+
+func WithBuildUpstream(b *buildConfig) {
+	b.buildUpstream = true
+}
+
+func WithAlwaysBuild(b *buildConfig) {
 	b.alwaysBuild = true
 }
 
+func WithExplain(b *buildConfig) {
+	b.explain = true
+}
+
+// WithKeepGoing makes the build go on after a failure, only the targets
+// depending on the failed ones not being built.
+func WithKeepGoing(b *buildConfig) {
+	b.keepGoing = true
+}
+
+// WithCompilationDatabase writes the compilation database of the built
+// components along with the build.
+func WithCompilationDatabase(b *buildConfig) {
+	b.compdb = true
+}
+
 func WithProfile(s string) BuildOption {
-	return func(b *Builder) {
+	return func(b *buildConfig) {
 		b.profile = s
 	}
 }
 
 func WithPlatform(s string) BuildOption {
-	return func(b *Builder) {
+	return func(b *buildConfig) {
 		b.platform = s
 	}
 }
 
 func WithJobs(j int) BuildOption {
-	return func(b *Builder) {
+	return func(b *buildConfig) {
 		b.jobs = j
 	}
 }
 
 func WithLuaContect(C *lua.LuaContext) BuildOption {
-	return func(b *Builder) {
+	return func(b *buildConfig) {
 		b.C = C
 	}
 }
+
+// WithEventHandler makes the events of the build be handled by handler
+// rather than printed on the console.
+func WithEventHandler(handler EventHandler) BuildOption {
+	return func(b *buildConfig) {
+		b.events = newEventEmitter(handler)
+	}
+}
+
+// WithSARIFReport writes the diagnostics of the build in file as a SARIF
+// report.
+func WithSARIFReport(file string) BuildOption {
+	return func(b *buildConfig) {
+		b.sarifReport = file
+	}
+}
+
+// WithContext makes the build stop when ctx is done, the running commands
+// being killed.
+func WithContext(ctx context.Context) BuildOption {
+	return func(b *buildConfig) {
+		b.ctx = ctx
+	}
+}
@@ -0,0 +1,36 @@
+package build
+
+import (
+	"path/filepath"
+
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+// GetComponentOutputs returns the files and directories, relative to the
+// project, where the outputs of the component are in the build directory
+// of p: its objects, its target, its exported headers and its package
+// files.
+func GetComponentOutputs(p *project.Project, c *project.Component) []string {
+	outputs := []string{
+		filepath.Join(p.Config.GetObjDirectory(true), c.Name),
+		filepath.Join(p.Config.GetExportedHeadersDirectory(true), c.Name),
+	}
+	targetDir := p.Config.GetTargetDirectory(c.Type, true)
+	switch c.Type {
+	case project.TypeExecutable, project.TypeTest:
+		outputs = append(outputs, filepath.Join(targetDir, c.GetTargetName(project.LinkageUnknown)))
+	case project.TypeLibrary:
+		// The linkage of the library depends on the profile
+		outputs = append(outputs,
+			filepath.Join(targetDir, c.GetTargetName(project.LinkageShared)),
+			filepath.Join(targetDir, c.GetTargetName(project.LinkageStatic)))
+	}
+	if hasPackageFiles(c) {
+		layout := getBuildPackageLayout(p)
+		prefix := p.Config.GetBuildDirectory(true)
+		outputs = append(outputs,
+			filepath.Join(prefix, layout.getPkgConfigFile(c)),
+			filepath.Join(prefix, layout.getCMakeDir(c)))
+	}
+	return outputs
+}
@@ -0,0 +1,102 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+)
+
+// compileCommand is an entry of a compilation database, as read by
+// clangd and the clang tools.
+type compileCommand struct {
+	Directory string   `json:"directory"`
+	Arguments []string `json:"arguments"`
+	File      string   `json:"file"`
+	Output    string   `json:"output"`
+}
+
+// getCompileCommands returns the commands used to compile each source of
+// the component.
+func (B *Builder) getCompileCommands() ([]compileCommand, error) {
+	if B.compiler == nil {
+		if err := B.prepareCompiler(); err != nil {
+			return nil, err
+		}
+	}
+	var commands []compileCommand
+	for _, source := range B.sourceFiles {
+		target, err := B.getObjectFile(source)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, compileCommand{
+			Directory: B.Project.Config.ProjectRootDirectory,
+			Arguments: B.compiler.CompileCommand(target, source),
+			File:      source,
+			Output:    target,
+		})
+	}
+	return commands, nil
+}
+
+// readCompilationDatabase returns the entries of the compilation
+// database at path, none if it does not exist.
+func readCompilationDatabase(path string) ([]compileCommand, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var commands []compileCommand
+	err = json.Unmarshal(data, &commands)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read compilation database '%s':\n\t%s", path, err.Error())
+	}
+	return commands, nil
+}
+
+// WriteCompilationDatabase writes the compile commands of the sources of
+// the components in the compilation database at the root of the
+// project. The entries of the other sources already in the database are
+// kept.
+func (PB *ProjectBuilder) WriteCompilationDatabase() error {
+	var commands []compileCommand
+	files := make(map[string]bool)
+	for _, c := range PB.components {
+		cmds, err := PB.builders[c].getCompileCommands()
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			files[cmd.File] = true
+		}
+		commands = append(commands, cmds...)
+	}
+
+	path := PB.Project.Config.GetCompilationDatabasePath(false)
+	previous, err := readCompilationDatabase(path)
+	if err != nil {
+		PB.events.emit(&Event{Kind: EventWarning, Message: err.Error() + ", overwriting it"})
+		previous = nil
+	}
+	for _, cmd := range previous {
+		if files[cmd.File] || cmd.Directory != PB.Project.Config.ProjectRootDirectory {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(cmd.Directory, cmd.File)); err == nil {
+			commands = append(commands, cmd)
+		}
+	}
+
+	data, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		return err
+	}
+	PB.events.emit(&Event{Kind: EventCompdbWritten, File: path})
+	return fsutil.WriteFileAtomic(path, data, 0o644)
+}
@@ -0,0 +1,235 @@
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/common/colors"
+	"github.com/gueckmooh/bs/pkg/diagnostics"
+)
+
+// EventKind tells what happened during a build.
+type EventKind string
+
+const (
+	EventComponentStarted  EventKind = "component_started"
+	EventComponentFinished EventKind = "component_finished"
+	EventRebuildExplained  EventKind = "rebuild_explained"
+	EventTargetsStarted    EventKind = "targets_started"
+	EventCompileStarted    EventKind = "compile_started"
+	EventFileCompiled      EventKind = "file_compiled"
+	EventLinkStarted       EventKind = "link_started"
+	EventTargetLinked      EventKind = "target_linked"
+	EventDiagnostics       EventKind = "diagnostics"
+	EventBuildSummary      EventKind = "build_summary"
+	EventBuildInterrupted  EventKind = "build_interrupted"
+	EventHeadersExporting  EventKind = "headers_exporting"
+	EventHeaderExported    EventKind = "header_exported"
+	EventHeaderRemoved     EventKind = "header_removed"
+	EventHooksStarted      EventKind = "hooks_started"
+	EventHookRun           EventKind = "hook_run"
+	EventFileWritten       EventKind = "file_written"
+	EventInstallStarted    EventKind = "install_started"
+	EventFileInstalled     EventKind = "file_installed"
+	EventCompdbWritten     EventKind = "compilation_database_written"
+	EventWarning           EventKind = "warning"
+)
+
+// The statuses of the component_finished and hook_run events.
+const (
+	StatusBuilt     = "built"
+	StatusUpToDate  = "up_to_date"
+	StatusSkipped   = "skipped"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// The stages of the hooks events.
+const (
+	StagePrebuild  = "prebuild"
+	StagePostbuild = "postbuild"
+)
+
+// Event is something that happened during a build. Only the fields
+// relevant to its kind are set, the durations are in seconds and the exit
+// status of a command is -1 when it could not be run.
+type Event struct {
+	Kind       EventKind `json:"event"`
+	Time       time.Time `json:"time"`
+	Component  string    `json:"component,omitempty"`
+	File       string    `json:"file,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	Archive    bool      `json:"archive,omitempty"`
+	Stage      string    `json:"stage,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	ExitStatus *int      `json:"exit_status,omitempty"`
+	Duration   *float64  `json:"duration,omitempty"`
+	Message    string    `json:"message,omitempty"`
+
+	Diagnostics []diagnostics.Diagnostic `json:"diagnostics,omitempty"`
+	Summary     *diagnostics.Summary     `json:"summary,omitempty"`
+}
+
+// EventHandler receives the events of a build, one at a time and in the
+// order they happened.
+type EventHandler interface {
+	HandleEvent(e *Event)
+}
+
+// eventEmitter serializes the events emitted by the jobs of a build, and
+// gathers the diagnostics they report.
+type eventEmitter struct {
+	mutex       sync.Mutex
+	handler     EventHandler
+	diagnostics []diagnostics.Diagnostic
+}
+
+func newEventEmitter(handler EventHandler) *eventEmitter {
+	return &eventEmitter{handler: handler}
+}
+
+func (ee *eventEmitter) emit(e *Event) {
+	e.Time = time.Now()
+	ee.mutex.Lock()
+	defer ee.mutex.Unlock()
+	ee.diagnostics = append(ee.diagnostics, e.Diagnostics...)
+	ee.handler.HandleEvent(e)
+}
+
+func (ee *eventEmitter) getDiagnostics() []diagnostics.Diagnostic {
+	ee.mutex.Lock()
+	defer ee.mutex.Unlock()
+	return ee.diagnostics
+}
+
+func (B *Builder) emit(e *Event) {
+	e.Component = B.component.Name
+	B.events.emit(e)
+}
+
+// emitDiagnostics reports the output printed by the tool building file,
+// if any, along with the diagnostics parsed from it.
+func (B *Builder) emitDiagnostics(file, output string) {
+	if output != "" {
+		B.emit(&Event{Kind: EventDiagnostics, File: file, Message: output,
+			Diagnostics: diagnostics.Parse(output)})
+	}
+}
+
+// getExitStatus returns the exit status of the command which returned
+// err.
+func getExitStatus(err error) *int {
+	status := 0
+	if err != nil {
+		status = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			status = exitErr.ExitCode()
+		}
+	}
+	return &status
+}
+
+func getDuration(start time.Time) *float64 {
+	duration := time.Since(start).Seconds()
+	return &duration
+}
+
+// ConsoleEventHandler prints the events of a build for a human reader.
+type ConsoleEventHandler struct {
+	out io.Writer
+	err io.Writer
+}
+
+func NewConsoleEventHandler() *ConsoleEventHandler {
+	return &ConsoleEventHandler{out: os.Stdout, err: os.Stderr}
+}
+
+func (h *ConsoleEventHandler) HandleEvent(e *Event) {
+	switch e.Kind {
+	case EventComponentStarted:
+		fmt.Fprintf(h.out, "--------------- Building component '%s'...\n", e.Component)
+	case EventComponentFinished:
+		switch e.Status {
+		case StatusBuilt:
+			fmt.Fprintf(h.out, "--------------- Build successful for '%s'\n", e.Component)
+		case StatusUpToDate:
+			fmt.Fprintf(h.out, "--------------- Nothing to be done for '%s'\n", e.Component)
+		case StatusFailed:
+			name := e.Component
+			if name == "" {
+				name = "project"
+			}
+			fmt.Fprintf(h.err, "Error while building componnent '%s':\n\t%s\n", name, e.Message)
+			fmt.Fprintf(h.out, "--------------- Failed to build component '%s'\n", name)
+		case StatusSkipped:
+			fmt.Fprintf(h.out, "--------------- Skipped component '%s': %s\n", e.Component, e.Message)
+		}
+	case EventRebuildExplained:
+		fmt.Fprintf(h.out, "%sExplain:%s %s needs to be rebuilt: %s\n",
+			colors.ColorCyan, colors.ColorReset, e.File, e.Message)
+	case EventTargetsStarted:
+		fmt.Fprintf(h.out, "%sBuilding targets...%s\n", colors.ColorGray, colors.ColorReset)
+	case EventCompileStarted:
+		fmt.Fprintf(h.out, "Compiling %s%s%s\n", colors.StyleBold, e.File, colors.StyleReset)
+	case EventLinkStarted:
+		verb := "Linking"
+		if e.Archive {
+			verb = "Archiving"
+		}
+		fmt.Fprintf(h.out, "%s %s%s%s\n", verb, colors.StyleBold, e.Target, colors.StyleReset)
+	case EventDiagnostics:
+		// The output of a tool is printed at once, so that the outputs of
+		// parallel jobs do not interleave
+		fmt.Fprintf(h.err, "%s", e.Message)
+	case EventBuildInterrupted:
+		fmt.Fprintf(h.out, "--------------- Build interrupted\n")
+	case EventBuildSummary:
+		color := colors.ColorYellow
+		if e.Summary.Errors > 0 {
+			color = colors.ColorRed
+		}
+		fmt.Fprintf(h.out, "%s%s%s\n", color, e.Summary, colors.ColorReset)
+	case EventHeadersExporting:
+		fmt.Fprintf(h.out, "%sExporting headers...%s\n", colors.ColorGray, colors.ColorReset)
+	case EventHeaderExported:
+		fmt.Fprintf(h.out, "Writing %s%s%s\n", colors.StyleBold, e.Target, colors.StyleReset)
+	case EventHeaderRemoved:
+		fmt.Fprintf(h.out, "Removing %s\n", e.Target)
+	case EventFileWritten:
+		fmt.Fprintf(h.out, "Writing %s%s%s\n", colors.StyleBold, e.File, colors.StyleReset)
+	case EventInstallStarted:
+		fmt.Fprintf(h.out, "--------------- Installing component '%s'...\n", e.Component)
+	case EventFileInstalled:
+		fmt.Fprintf(h.out, "Installing %s%s%s\n", colors.StyleBold, e.Target, colors.StyleReset)
+	case EventHooksStarted:
+		fmt.Fprintf(h.out, "%sRunning %s hooks...%s\n", colors.ColorGray, e.Stage, colors.ColorReset)
+	case EventCompdbWritten:
+		fmt.Fprintf(h.out, "%sWriting compilation database %s%s\n",
+			colors.ColorGray, filepath.Base(e.File), colors.ColorReset)
+	case EventWarning:
+		fmt.Fprintf(h.err, "%sWarning:%s %s\n", colors.ColorYellow, colors.ColorReset, e.Message)
+	}
+}
+
+// JSONEventHandler writes the events of a build as JSON objects, one per
+// line.
+type JSONEventHandler struct {
+	encoder *json.Encoder
+}
+
+func NewJSONEventHandler(w io.Writer) *JSONEventHandler {
+	return &JSONEventHandler{encoder: json.NewEncoder(w)}
+}
+
+func (h *JSONEventHandler) HandleEvent(e *Event) {
+	// There is nowhere to report the event could not be written
+	h.encoder.Encode(e)
+}
@@ -2,13 +2,10 @@ package build
 
 import (
 	"bytes"
-	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"text/template"
 
-	"github.com/gueckmooh/bs/pkg/common/colors"
 	"github.com/gueckmooh/bs/pkg/fsutil"
 	"github.com/gueckmooh/bs/pkg/globbing"
 )
@@ -130,8 +127,8 @@ func (B *Builder) doCopyFiles(copies map[string]string) error {
 		if err != nil {
 			return err
 		}
-		fmt.Printf("Writing %s%s%s\n", colors.StyleBold, to, colors.StyleReset)
-		err = ioutil.WriteFile(to, []byte(tramp), 0o600)
+		B.emit(&Event{Kind: EventHeaderExported, File: from, Target: to})
+		err = fsutil.WriteFileAtomic(to, []byte(tramp), 0o600)
 		if err != nil {
 			return err
 		}
@@ -141,7 +138,7 @@ func (B *Builder) doCopyFiles(copies map[string]string) error {
 
 func (B *Builder) doRemoveFiles(removes []string) error {
 	for _, file := range removes {
-		fmt.Printf("Removing %s\n", file)
+		B.emit(&Event{Kind: EventHeaderRemoved, Target: file})
 		err := os.Remove(file)
 		if err != nil {
 			return err
@@ -150,36 +147,46 @@ func (B *Builder) doRemoveFiles(removes []string) error {
 	return nil
 }
 
-func (B *Builder) exportHeaders() (bool, error) {
-	if B.component.ExportedHeaders == nil {
-		return false, nil
-	}
+// getMappedFiles returns the files of the component matching the
+// patterns of mapping, relative to the component directory, along with
+// the path they are mapped to by the replacement of the pattern.
+func (B *Builder) getMappedFiles(mapping map[string]string) (map[string]string, error) {
 	allCopies := make(map[string]string)
-	for k, v := range B.component.ExportedHeaders {
+	for k, v := range mapping {
 		p := globbing.NewPatternReplace(k, v)
 		err := p.Compile()
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		files, err := fsutil.GetMatchingRepFiles(p, B.component.Path)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		copies, err := B.getCopiesForExportHeaders(p, B.component.Path, files)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 		for k, v := range copies {
 			allCopies[k] = v
 		}
 	}
+	return allCopies, nil
+}
+
+func (B *Builder) exportHeaders() (bool, error) {
+	if B.component.ExportedHeaders == nil {
+		return false, nil
+	}
+	allCopies, err := B.getMappedFiles(B.component.ExportedHeaders)
+	if err != nil {
+		return false, err
+	}
 	copies, removes, err := B.getFilesToCopyOrRemove(allCopies)
 	if err != nil {
 		return false, err
 	}
 	if len(copies) > 0 || len(removes) > 0 {
-		fmt.Printf("%sExporting headers...%s\n",
-			colors.ColorGray, colors.ColorReset)
+		B.emit(&Event{Kind: EventHeadersExporting})
 		if len(copies) > 0 {
 			err := B.doCopyFiles(copies)
 			if err != nil {
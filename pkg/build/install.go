@@ -0,0 +1,217 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+const (
+	DefaultInstallPrefix     = "/usr/local"
+	DefaultInstallBinDir     = "bin"
+	DefaultInstallLibDir     = "lib"
+	DefaultInstallIncludeDir = "include"
+)
+
+type installConfig struct {
+	prefix     string
+	destDir    string
+	binDir     string
+	libDir     string
+	includeDir string
+}
+
+func defaultInstallConfig() installConfig {
+	return installConfig{
+		prefix:     DefaultInstallPrefix,
+		binDir:     DefaultInstallBinDir,
+		libDir:     DefaultInstallLibDir,
+		includeDir: DefaultInstallIncludeDir,
+	}
+}
+
+type InstallOption func(c *installConfig)
+
+func WithPrefix(prefix string) InstallOption {
+	return func(c *installConfig) {
+		c.prefix = prefix
+	}
+}
+
+// WithDestDir stages the installation in destDir, the files being
+// installed as if destDir was the root directory.
+func WithDestDir(destDir string) InstallOption {
+	return func(c *installConfig) {
+		c.destDir = destDir
+	}
+}
+
+func WithBinDir(dir string) InstallOption {
+	return func(c *installConfig) {
+		c.binDir = dir
+	}
+}
+
+func WithLibDir(dir string) InstallOption {
+	return func(c *installConfig) {
+		c.libDir = dir
+	}
+}
+
+func WithIncludeDir(dir string) InstallOption {
+	return func(c *installConfig) {
+		c.includeDir = dir
+	}
+}
+
+// getDirectory returns the directory dir, relative to the prefix, once
+// installed and the directory the files of dir are written to.
+func (c *installConfig) getDirectory(dir string) (string, string) {
+	installed := filepath.Join(c.prefix, dir)
+	if c.destDir == "" {
+		return installed, installed
+	}
+	return installed, filepath.Join(c.destDir, installed)
+}
+
+func (c *installConfig) getTargetDirectory(ty project.ComponentType) string {
+	if ty == project.TypeLibrary {
+		return c.libDir
+	}
+	return c.binDir
+}
+
+func (B *Builder) installFile(from, to string) error {
+	err := fsutil.MkdirRecIfNotExist(filepath.Dir(to))
+	if err != nil {
+		return err
+	}
+	B.emit(&Event{Kind: EventFileInstalled, File: from, Target: to})
+	// The installed file may be in use, it is replaced rather than
+	// overwritten
+	if err := os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return fsutil.CopyFile(from, to)
+}
+
+// installTarget installs the target of the component. The target is
+// relinked when its run path differs once installed.
+func (B *Builder) installTarget(config *installConfig) error {
+	g := B.filesGraph
+	target := g.GetVertexAttribute(B.targetVertex).name
+	installedDir, dir := config.getDirectory(config.getTargetDirectory(B.component.Type))
+	installedLibDir, _ := config.getDirectory(config.libDir)
+	to := filepath.Join(dir, filepath.Base(target))
+
+	profile, err := B.getProfileForComponent(B.component)
+	if err != nil {
+		return err
+	}
+	buildRunPaths, err := B.getRunPaths(profile,
+		B.Project.Config.GetTargetDirectory(B.component.Type, false), B.Project.Config.GetLibDirectory(false))
+	if err != nil {
+		return err
+	}
+	installRunPaths, err := B.getRunPaths(profile, installedDir, installedLibDir)
+	if err != nil {
+		return err
+	}
+	if functional.ListEqual(buildRunPaths, installRunPaths) {
+		return B.installFile(target, to)
+	}
+
+	comp, err := B.newCompilerForLayout(installedDir, installedLibDir)
+	if err != nil {
+		return err
+	}
+	sources, err := B.getTargetSources()
+	if err != nil {
+		return err
+	}
+	err = fsutil.MkdirRecIfNotExist(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return B.linkFiles(comp, to, sources)
+}
+
+// installMappedFiles installs the files of the component matching the
+// patterns of mapping in dir, with the path given by the replacement of
+// the pattern.
+func (B *Builder) installMappedFiles(mapping map[string]string, dir string) error {
+	files, err := B.getMappedFiles(mapping)
+	if err != nil {
+		return err
+	}
+	var froms []string
+	for from := range files {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	for _, from := range froms {
+		err := B.installFile(filepath.Join(B.component.Path, from), filepath.Join(dir, files[from]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Install installs the target, the exported headers, the package files
+// and the files of the install section of the component.
+func (B *Builder) Install(config *installConfig) error {
+	if B.hasTarget() {
+		if err := B.installTarget(config); err != nil {
+			return err
+		}
+	}
+	_, includeDir := config.getDirectory(config.includeDir)
+	if err := B.installMappedFiles(B.component.ExportedHeaders, includeDir); err != nil {
+		return err
+	}
+	prefix, root := config.getDirectory("")
+	if hasPackageFiles(B.component) {
+		layout := packageLayout{
+			prefix:     prefix,
+			libDir:     config.libDir,
+			includeDir: config.includeDir,
+		}
+		if err := B.writePackageFiles(layout, root); err != nil {
+			return err
+		}
+	}
+	return B.installMappedFiles(B.component.Install, root)
+}
+
+// Install installs the components of the project builder, which must
+// have been built, the tests excepted.
+func (PB *ProjectBuilder) Install(opts ...InstallOption) error {
+	config := defaultInstallConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if !filepath.IsAbs(config.prefix) {
+		return fmt.Errorf("The install prefix must be an absolute path, got '%s'", config.prefix)
+	}
+
+	for _, c := range PB.components {
+		if c.Type == project.TypeTest {
+			continue
+		}
+		PB.events.emit(&Event{Kind: EventInstallStarted, Component: c.Name})
+		err := PB.builders[c].Install(&config)
+		if err != nil {
+			return fmt.Errorf("Could not install component '%s':\n\t%s", c.Name, err.Error())
+		}
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ func getLanguageSrcMatcher(langID project.LanguageID) *globbing.Pattern {
 	switch langID {
 	case project.LangCPP:
 		return globbing.NewRawPattern(`.*\.(cpp|C|cc|cxx)`)
+	case project.LangC:
+		return globbing.NewRawPattern(`.*\.c`)
 	}
 	return nil
 }
@@ -17,6 +19,8 @@ func getLanguageHeaderMatcher(langID project.LanguageID) *globbing.Pattern {
 	switch langID {
 	case project.LangCPP:
 		return globbing.NewRawPattern(`.*\.(hpp|h|hh|hxx)`)
+	case project.LangC:
+		return globbing.NewRawPattern(`.*\.h`)
 	}
 	return nil
 }
@@ -21,7 +21,11 @@ func (B *Builder) RunLuaFunction(F *lua.LFunction) error {
 	for _, v := range args {
 		B.C.L.Push(v)
 	}
-	B.C.L.Call(len(args), 0)
+	// The lua error tells where the function failed
+	err := B.C.L.PCall(len(args), 0, nil)
+	if err != nil {
+		return fmt.Errorf("Error while running function:\n\t%s", err.Error())
+	}
 	return nil
 }
 
@@ -0,0 +1,271 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+// packageLayout tells where the files of the packages generated for the
+// components are, either in the build tree or once installed.
+type packageLayout struct {
+	// prefix is the absolute directory the other ones are relative to
+	prefix     string
+	libDir     string
+	includeDir string
+	// componentIncludeDirs is set when the headers of each component
+	// are exported in their own directory of includeDir
+	componentIncludeDirs bool
+}
+
+// getBuildPackageLayout returns the layout of the build tree.
+func getBuildPackageLayout(p *project.Project) packageLayout {
+	return packageLayout{
+		prefix:               p.Config.GetBuildDirectory(false),
+		libDir:               p.Config.LibDirectory,
+		includeDir:           p.Config.ExportHeadersDirectory,
+		componentIncludeDirs: true,
+	}
+}
+
+func (l *packageLayout) getIncludeDir(c *project.Component) string {
+	if l.componentIncludeDirs {
+		return filepath.ToSlash(filepath.Join(l.includeDir, c.Name))
+	}
+	return filepath.ToSlash(l.includeDir)
+}
+
+func (l *packageLayout) getPkgConfigFile(c *project.Component) string {
+	return filepath.Join(l.libDir, "pkgconfig", c.Name+".pc")
+}
+
+func (l *packageLayout) getCMakeDir(c *project.Component) string {
+	return filepath.Join(l.libDir, "cmake", c.Name)
+}
+
+func (l *packageLayout) getCMakeConfigFile(c *project.Component) string {
+	return filepath.Join(l.getCMakeDir(c), c.Name+"Config.cmake")
+}
+
+const pkgConfigTemplate = `# Generated by bs for the component {{.Name}} of {{.Project}}
+prefix={{.Prefix}}
+libdir=${prefix}/{{.LibDir}}
+includedir=${prefix}/{{.IncludeDir}}
+
+Name: {{.Name}}
+Description: {{.Name}} component of {{.Project}}
+Version: {{.Version}}
+{{- if or .Requires .Packages}}
+Requires: {{join (concat .Requires .Packages) ", "}}
+{{- end}}
+{{- if .Libs}}
+Libs: {{join .Libs " "}}
+{{- end}}
+{{- if .LibsPrivate}}
+Libs.private: {{join .LibsPrivate " "}}
+{{- end}}
+Cflags: -I${includedir}{{range .Cflags}} {{.}}{{end}}
+`
+
+const cmakeConfigTemplate = `# Generated by bs for the component {{.Name}} of {{.Project}}
+{{- range .Requires}}
+include("${CMAKE_CURRENT_LIST_DIR}/../{{.}}/{{.}}Config.cmake")
+{{- end}}
+get_filename_component(_bs_prefix "${CMAKE_CURRENT_LIST_DIR}/{{.CMakePrefix}}" ABSOLUTE)
+
+if(NOT TARGET {{.Name}}::{{.Name}})
+  add_library({{.Name}}::{{.Name}} {{.Kind}} IMPORTED)
+  set_target_properties({{.Name}}::{{.Name}} PROPERTIES
+{{- if .Target}}
+    IMPORTED_LOCATION "${_bs_prefix}/{{.LibDir}}/{{.Target}}"
+{{- end}}
+    INTERFACE_INCLUDE_DIRECTORIES "${_bs_prefix}/{{.IncludeDir}}"
+{{- if .CompileOptions}}
+    INTERFACE_COMPILE_OPTIONS "{{join .CompileOptions ";"}}"
+{{- end}}
+{{- if .LinkLibraries}}
+    INTERFACE_LINK_LIBRARIES "{{join .LinkLibraries ";"}}"
+{{- end}})
+endif()
+
+unset(_bs_prefix)
+`
+
+type packageInfo struct {
+	Name           string
+	Project        string
+	Version        string
+	Prefix         string
+	CMakePrefix    string
+	LibDir         string
+	IncludeDir     string
+	Kind           string
+	Target         string
+	Requires       []string
+	Packages       []string
+	Cflags         []string
+	Libs           []string
+	LibsPrivate    []string
+	LinkLibraries  []string
+	CompileOptions []string
+}
+
+func hasPackageFiles(c *project.Component) bool {
+	return c.Type == project.TypeLibrary || c.Type == project.TypeHeaders
+}
+
+// getPackageInfo returns what the package files of the component describe
+// when they are laid out according to layout.
+func (B *Builder) getPackageInfo(layout packageLayout) (*packageInfo, error) {
+	c := B.component
+	version := B.Project.Version
+	if version == "" {
+		version = "0"
+	}
+	cmakePrefix, err := filepath.Rel(filepath.Join(layout.prefix, layout.getCMakeDir(c)), layout.prefix)
+	if err != nil {
+		return nil, err
+	}
+	info := &packageInfo{
+		Name:        c.Name,
+		Project:     B.Project.Name,
+		Version:     version,
+		Prefix:      layout.prefix,
+		CMakePrefix: filepath.ToSlash(cmakePrefix),
+		LibDir:      filepath.ToSlash(layout.libDir),
+		IncludeDir:  layout.getIncludeDir(c),
+		Kind:        "INTERFACE",
+	}
+	for _, dep := range c.DirectDependencies {
+		if hasPackageFiles(dep) {
+			info.Requires = append(info.Requires, dep.Name)
+			info.LinkLibraries = append(info.LinkLibraries, dep.Name+"::"+dep.Name)
+		}
+	}
+	packages, err := B.getPackages(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range packages {
+		info.Packages = append(info.Packages, pkg.Spec)
+		info.CompileOptions = append(info.CompileOptions, pkg.CFlags...)
+		info.LinkLibraries = append(info.LinkLibraries, pkg.Libs...)
+	}
+
+	profile, err := B.getProfileForComponent(c)
+	if err != nil {
+		return nil, err
+	}
+	cppOpts, cOpts := getProfilePublicCompileOptions(profile)
+	info.Cflags = functional.ListUniq(append(cppOpts, cOpts...))
+	info.CompileOptions = append(info.CompileOptions, info.Cflags...)
+	publicLinkOptions := getProfilePublicLinkOptions(profile)
+	info.LinkLibraries = append(info.LinkLibraries, publicLinkOptions...)
+	if c.Type != project.TypeLibrary {
+		info.Libs = publicLinkOptions
+		return info, nil
+	}
+
+	linkOptions := getProfileLinkOptions(profile)
+	info.Target = c.GetTargetName(B.linkages[c])
+	info.Libs = append([]string{"-L${libdir}", "-l" + c.Name}, publicLinkOptions...)
+	if B.linkages[c] == project.LinkageStatic {
+		// The link options are needed by the users of a static library
+		info.Kind = "STATIC"
+		info.Libs = append(info.Libs, linkOptions...)
+		info.LinkLibraries = append(info.LinkLibraries, linkOptions...)
+	} else {
+		info.Kind = "SHARED"
+		info.LibsPrivate = linkOptions
+	}
+	return info, nil
+}
+
+func executePackageTemplate(name, text string, info *packageInfo) ([]byte, error) {
+	t, err := template.New(name).Funcs(template.FuncMap{
+		"join": strings.Join,
+		"concat": func(a, b []string) []string {
+			return append(append([]string{}, a...), b...)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, info)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFileIfChanged writes content in file unless it is already its
+// content, and tells if it was written.
+func writeFileIfChanged(file string, content []byte) (bool, error) {
+	old, err := ioutil.ReadFile(file)
+	if err == nil && bytes.Equal(old, content) {
+		return false, nil
+	}
+	err = fsutil.MkdirRecIfNotExist(filepath.Dir(file))
+	if err != nil {
+		return false, err
+	}
+	return true, fsutil.WriteFileAtomic(file, content, 0o644)
+}
+
+// writePackageFiles writes the pkg-config and CMake package files of the
+// component, laid out according to layout, root being the directory
+// the prefix of the layout is written to.
+func (B *Builder) writePackageFiles(layout packageLayout, root string) error {
+	info, err := B.getPackageInfo(layout)
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		path, template string
+	}{
+		{layout.getPkgConfigFile(B.component), pkgConfigTemplate},
+		{layout.getCMakeConfigFile(B.component), cmakeConfigTemplate},
+	}
+	for _, f := range files {
+		content, err := executePackageTemplate(filepath.Base(f.path), f.template, info)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(root, f.path)
+		written, err := writeFileIfChanged(path, content)
+		if err != nil {
+			return fmt.Errorf("Could not write package file '%s':\n\t%s", path, err.Error())
+		}
+		if written {
+			if rel, err := filepath.Rel(B.Project.Config.ProjectRootDirectory, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+			B.emit(&Event{Kind: EventFileWritten, File: path})
+		}
+	}
+	return nil
+}
+
+// writeBuildPackageFiles writes the package files of the libraries and
+// headers components in the build tree.
+func (PB *ProjectBuilder) writeBuildPackageFiles() error {
+	layout := getBuildPackageLayout(PB.Project)
+	for _, c := range PB.components {
+		if !hasPackageFiles(c) || PB.isBroken(c) {
+			continue
+		}
+		err := PB.builders[c].writePackageFiles(layout, layout.prefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	alist "github.com/gueckmooh/bs/pkg/adjacency_list"
+	"github.com/gueckmooh/bs/pkg/builddb"
+	"github.com/gueckmooh/bs/pkg/diagnostics"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+type stepKind int8
+
+const (
+	stepCompile stepKind = iota
+	stepLink
+	stepPostBuild
+)
+
+// buildStep is a vertex of the project build graph. Its out edges point
+// to the steps that must be done before it can be run.
+type buildStep struct {
+	kind    stepKind
+	builder *Builder
+	vertex  alist.VertexDescriptor
+}
+
+func (s *buildStep) isLocal() bool {
+	// Lua hooks are run on the lua state, which cannot be shared
+	// between goroutines
+	return s.kind == stepPostBuild
+}
+
+func (s *buildStep) run() error {
+	switch s.kind {
+	case stepCompile:
+		return s.builder.compileObject(s.vertex)
+	case stepLink:
+		return s.builder.linkTarget()
+	case stepPostBuild:
+		return s.builder.PostBuild()
+	}
+	return fmt.Errorf("Unknown build step kind %d", s.kind)
+}
+
+func (s *buildStep) String() string {
+	switch s.kind {
+	case stepCompile:
+		return s.builder.filesGraph.GetVertexAttribute(s.vertex).name
+	case stepLink:
+		return s.builder.filesGraph.GetVertexAttribute(s.builder.targetVertex).name
+	case stepPostBuild:
+		return fmt.Sprintf("postbuild(%s)", s.builder.component.Name)
+	}
+	return ""
+}
+
+// componentFailure is an error which made a component fail to build, the
+// component being nil when the error is not specific to one.
+type componentFailure struct {
+	component *project.Component
+	err       error
+}
+
+// ProjectBuilder builds several components of a project at once. The
+// files graphs of all the components are merged in a single build graph
+// which is scheduled on a single pool of jobs.
+type ProjectBuilder struct {
+	buildConfig
+	Project    *project.Project
+	components []*project.Component
+	builders   map[*project.Component]*Builder
+	graph      *alist.Graph[buildStep, alist.AttributeNone]
+	finalSteps map[*project.Component]alist.VertexDescriptor
+	failures   []componentFailure
+	skipped    map[*project.Component]bool
+	db         *builddb.Database
+}
+
+func NewProjectBuilder(p *project.Project, ctbs []string, opts ...BuildOption) (*ProjectBuilder, error) {
+	config := defaultBuildConfig()
+	for _, opt := range opts {
+		opt(&config)
+	}
+	config.pkgConfig = newPkgConfig(p)
+
+	PB := &ProjectBuilder{
+		buildConfig: config,
+		Project:     p,
+		builders:    make(map[*project.Component]*Builder),
+		graph:       alist.NewGraph[buildStep, alist.AttributeNone](alist.DirectedGraph),
+		finalSteps:  make(map[*project.Component]alist.VertexDescriptor),
+		skipped:     make(map[*project.Component]bool),
+	}
+
+	var roots []*project.Component
+	for _, ctb := range ctbs {
+		c, err := p.GetComponent(ctb)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, c)
+	}
+	PB.components = PB.sortComponents(roots)
+
+	db, err := builddb.Open(p.Config.GetBuildDatabasePath(true))
+	if err != nil {
+		PB.events.emit(&Event{Kind: EventWarning, Message: err.Error() + ", rebuilding everything"})
+		db = builddb.New(p.Config.GetBuildDatabasePath(true))
+	}
+	PB.db = db
+
+	for _, c := range PB.components {
+		builder, err := newBuilderWithConfig(p, c.Name, config, db)
+		if err != nil {
+			return nil, err
+		}
+		PB.builders[c] = builder
+	}
+
+	return PB, nil
+}
+
+// Emit reports e to the event handler of the build, for the work done
+// along with it such as writing the report of its tests.
+func (PB *ProjectBuilder) Emit(e *Event) {
+	PB.events.emit(e)
+}
+
+// GetComponents returns the components built by the project builder,
+// the dependencies of a component coming before it.
+func (PB *ProjectBuilder) GetComponents() []*project.Component {
+	return PB.components
+}
+
+// GetInputFiles returns the files the objects of the component were built
+// from, relative to the project, once it was built.
+func (PB *ProjectBuilder) GetInputFiles(c *project.Component) []string {
+	if B, ok := PB.builders[c]; ok {
+		return B.getInputFiles()
+	}
+	return nil
+}
+
+// sortComponents returns the components to build, the dependencies
+// of a component coming before it.
+func (PB *ProjectBuilder) sortComponents(roots []*project.Component) []*project.Component {
+	var components []*project.Component
+	visited := make(map[*project.Component]bool)
+	var processComponent func(c *project.Component, isRoot bool)
+	processComponent = func(c *project.Component, isRoot bool) {
+		if visited[c] {
+			return
+		}
+		if !isRoot && !PB.buildUpstream && !functional.ListIn(roots, c) {
+			return
+		}
+		visited[c] = true
+		for _, d := range c.DirectDependencies {
+			processComponent(d, false)
+		}
+		components = append(components, c)
+	}
+	for _, c := range roots {
+		processComponent(c, true)
+	}
+	return components
+}
+
+// getLinkedDependencies returns the dependencies c is linked against
+// that are built along with it.
+func (PB *ProjectBuilder) getLinkedDependencies(c *project.Component) []*Builder {
+	var deps []*Builder
+	for _, d := range PB.builders[c].linkedComponents {
+		if builder, ok := PB.builders[d]; ok && builder.hasTarget() {
+			deps = append(deps, builder)
+		}
+	}
+	return deps
+}
+
+func (PB *ProjectBuilder) hasFailed(c *project.Component) bool {
+	return functional.ListAnyOf(PB.failures, func(f componentFailure) bool { return f.component == c })
+}
+
+// isBroken tells whether the component failed to build or was skipped.
+func (PB *ProjectBuilder) isBroken(c *project.Component) bool {
+	return PB.skipped[c] || PB.hasFailed(c)
+}
+
+// skipIfDependencyIsBroken marks the component as skipped if one of the
+// components it depends on failed to build or was skipped itself.
+func (PB *ProjectBuilder) skipIfDependencyIsBroken(c *project.Component) bool {
+	if !PB.hasFailed(c) && functional.ListAnyOf(c.Dependencies, PB.isBroken) {
+		PB.skipped[c] = true
+	}
+	return PB.skipped[c]
+}
+
+// propagateRebuilds marks the targets linked against a target to be
+// rebuilt as needing to be rebuilt too.
+func (PB *ProjectBuilder) propagateRebuilds() {
+	for _, c := range PB.components {
+		B := PB.builders[c]
+		if PB.isBroken(c) || !B.hasTarget() || B.needsToBeRebuilt() {
+			continue
+		}
+		for _, dep := range PB.getLinkedDependencies(c) {
+			if dep.needsToBeRebuilt() {
+				B.filesGraph.GetVertexAttribute(B.targetVertex).markForRebuild(
+					fmt.Sprintf("%s is rebuilt", dep.filesGraph.GetVertexAttribute(dep.targetVertex).name))
+				break
+			}
+		}
+	}
+}
+
+func (PB *ProjectBuilder) addStep(kind stepKind, B *Builder, v alist.VertexDescriptor) alist.VertexDescriptor {
+	return PB.graph.AddVertex(&buildStep{
+		kind:    kind,
+		builder: B,
+		vertex:  v,
+	})
+}
+
+// computeBuildGraph adds the steps needed to build each component to
+// the build graph: objects, then the target once its objects and the
+// targets it depends on are built, then the postbuild hooks.
+func (PB *ProjectBuilder) computeBuildGraph() error {
+	for _, c := range PB.components {
+		B := PB.builders[c]
+		if PB.isBroken(c) || !B.hasTarget() || !B.needsToBeRebuilt() {
+			continue
+		}
+		link := PB.addStep(stepLink, B, B.targetVertex)
+		objects, err := B.getObjectsToCompile()
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			PB.graph.AddEdge(link, PB.addStep(stepCompile, B, o))
+		}
+		for _, dep := range c.Dependencies {
+			if v, ok := PB.finalSteps[dep]; ok {
+				PB.graph.AddEdge(link, v)
+			}
+		}
+		PB.finalSteps[c] = link
+		if len(c.PostbuildActions) > 0 {
+			postbuild := PB.addStep(stepPostBuild, B, B.targetVertex)
+			PB.graph.AddEdge(postbuild, link)
+			PB.finalSteps[c] = postbuild
+		}
+	}
+	return nil
+}
+
+// isStopped tells whether the build is stopped because a component failed
+// to build.
+func (PB *ProjectBuilder) isStopped() bool {
+	return !PB.keepGoing && len(PB.failures) > 0
+}
+
+// prepare prepares the components and computes the build graph. When
+// keeping going, the components which fail to be prepared are not built,
+// nor the ones depending on them.
+func (PB *ProjectBuilder) prepare() error {
+	for _, c := range PB.components {
+		if err := PB.ctx.Err(); err != nil {
+			return err
+		}
+		if PB.skipIfDependencyIsBroken(c) {
+			continue
+		}
+		PB.events.emit(&Event{Kind: EventComponentStarted, Component: c.Name})
+		err := PB.builders[c].Prepare()
+		if err != nil {
+			PB.failures = append(PB.failures, componentFailure{c, err})
+			if PB.isStopped() {
+				return nil
+			}
+		}
+	}
+	PB.propagateRebuilds()
+	if PB.explain {
+		for _, c := range PB.components {
+			if !PB.isBroken(c) {
+				PB.builders[c].explainRebuilds()
+			}
+		}
+	}
+	return PB.computeBuildGraph()
+}
+
+// tryBuild builds the components, the errors making components fail to
+// build being recorded rather than returned.
+func (PB *ProjectBuilder) tryBuild() error {
+	err := PB.prepare()
+	if err != nil || PB.isStopped() {
+		return err
+	}
+	if PB.compdb {
+		err = PB.WriteCompilationDatabase()
+		if err != nil {
+			return err
+		}
+	}
+	if len(PB.graph.GetVertices()) == 0 {
+		return PB.writeBuildPackageFiles()
+	}
+
+	PB.events.emit(&Event{Kind: EventTargetsStarted})
+	failures, err := runStepsGraph(PB.ctx, PB.graph, PB.jobs, PB.keepGoing)
+	for _, f := range failures {
+		PB.failures = append(PB.failures, componentFailure{f.step.builder.component, f.err})
+	}
+	if dberr := PB.db.Save(); dberr != nil && err == nil {
+		return dberr
+	}
+	if err != nil || PB.isStopped() {
+		return err
+	}
+	return PB.writeBuildPackageFiles()
+}
+
+// reportDiagnostics prints the summary of the diagnostics of the build,
+// and writes them in the SARIF report if one is requested.
+func (PB *ProjectBuilder) reportDiagnostics() error {
+	diags := PB.events.getDiagnostics()
+	if len(diags) > 0 {
+		summary := diagnostics.Summarize(diags)
+		PB.events.emit(&Event{Kind: EventBuildSummary, Summary: &summary})
+	}
+	if PB.sarifReport == "" {
+		return nil
+	}
+	err := diagnostics.WriteSARIFReport(PB.sarifReport, "bs", diags)
+	if err != nil {
+		return err
+	}
+	PB.events.emit(&Event{Kind: EventFileWritten, File: PB.sarifReport})
+	return nil
+}
+
+// reportComponents reports whether each component was built, and the
+// errors of the ones which failed. When not keeping going, only the
+// failure which stopped the build is reported.
+func (PB *ProjectBuilder) reportComponents() {
+	for _, f := range PB.failures {
+		if f.component == nil || !PB.keepGoing {
+			e := &Event{Kind: EventComponentFinished, Status: StatusFailed, Message: f.err.Error()}
+			if f.component != nil {
+				e.Component = f.component.Name
+			}
+			PB.events.emit(e)
+		}
+	}
+	if !PB.keepGoing && len(PB.failures) > 0 {
+		return
+	}
+	for _, c := range PB.components {
+		e := &Event{Kind: EventComponentFinished, Component: c.Name, Status: StatusUpToDate}
+		B := PB.builders[c]
+		if PB.hasFailed(c) {
+			e.Status = StatusFailed
+			var messages []string
+			for _, f := range PB.failures {
+				if f.component == c {
+					messages = append(messages, f.err.Error())
+				}
+			}
+			e.Message = strings.Join(messages, "\n\t")
+		} else if PB.skipIfDependencyIsBroken(c) {
+			e.Status = StatusSkipped
+			broken := functional.ListFilter(c.Dependencies, PB.isBroken)
+			e.Message = fmt.Sprintf("Dependency '%s' was not built", broken[0].Name)
+		} else if B.headersExported || B.needsToBeRebuilt() {
+			e.Status = StatusBuilt
+		}
+		PB.events.emit(e)
+	}
+}
+
+// getBuildError returns the error telling which components failed to
+// build, if any.
+func (PB *ProjectBuilder) getBuildError() error {
+	var names []string
+	for _, f := range PB.failures {
+		name := "project"
+		if f.component != nil {
+			name = f.component.Name
+		}
+		names = append(names, name)
+	}
+	names = functional.ListUniq(names)
+	switch len(names) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("Build of component '%s' failed", names[0])
+	}
+	return fmt.Errorf("Build of components '%s' failed", strings.Join(names, "', '"))
+}
+
+// Build builds all the components of the project builder.
+func (PB *ProjectBuilder) Build() error {
+	err := PB.tryBuild()
+	if PB.ctx.Err() != nil {
+		// The steps which failed were most likely killed
+		PB.events.emit(&Event{Kind: EventBuildInterrupted})
+		return fmt.Errorf("Build interrupted")
+	}
+	if err != nil {
+		PB.failures = append(PB.failures, componentFailure{nil, err})
+	}
+	PB.reportComponents()
+	err = PB.getBuildError()
+	if reportErr := PB.reportDiagnostics(); reportErr != nil && err == nil {
+		return reportErr
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package build
+
+import (
+	"context"
+	"sort"
+
+	alist "github.com/gueckmooh/bs/pkg/adjacency_list"
+	"github.com/gueckmooh/bs/pkg/bucket"
+)
+
+type stepResult struct {
+	v   alist.VertexDescriptor
+	err error
+}
+
+// stepFailure is a step which failed with err.
+type stepFailure struct {
+	step *buildStep
+	err  error
+}
+
+// runStepsGraph runs every step of g once all the steps it depends on
+// (its out edges) are done. Steps are run on a pool of jobs workers,
+// except the local ones which are run on the calling goroutine. Once
+// a step failed, no new step is started and the failed step is returned
+// with its error when the running steps are done. If keepGoing is set,
+// only the steps depending on a failed step are not run, and all the
+// failed steps are returned. Once ctx is done, no new step is started
+// and its error is returned when the running steps are done.
+func runStepsGraph(ctx context.Context, g *alist.Graph[buildStep, alist.AttributeNone], jobs int,
+	keepGoing bool,
+) ([]stepFailure, error) {
+	vertices := g.GetVertices()
+	sort.Slice(vertices, func(i, j int) bool { return vertices[i] < vertices[j] })
+	pending := make(map[alist.VertexDescriptor]int)
+	dependents := make(map[alist.VertexDescriptor][]alist.VertexDescriptor)
+	var ready []alist.VertexDescriptor
+	for _, v := range vertices {
+		deps, err := g.Neighbors(v)
+		if err != nil {
+			return nil, err
+		}
+		pending[v] = len(deps)
+		for _, d := range deps {
+			dependents[d] = append(dependents[d], v)
+		}
+		if len(deps) == 0 {
+			ready = append(ready, v)
+		}
+	}
+
+	if jobs < 1 {
+		jobs = 1
+	}
+	b := bucket.NewBucketWithContext(ctx, int64(jobs))
+	done := make(chan stepResult, len(vertices))
+	var failures []stepFailure
+	skipped := make(map[alist.VertexDescriptor]bool)
+	running := 0
+	remaining := len(vertices)
+
+	// skipDependents marks the steps depending on v as not to be run
+	var skipDependents func(v alist.VertexDescriptor)
+	skipDependents = func(v alist.VertexDescriptor) {
+		for _, d := range dependents[v] {
+			if !skipped[d] {
+				skipped[d] = true
+				remaining--
+				skipDependents(d)
+			}
+		}
+	}
+
+	for remaining > 0 {
+		for ctx.Err() == nil && (keepGoing || len(failures) == 0) && len(ready) > 0 {
+			v := ready[0]
+			ready = ready[1:]
+			if skipped[v] {
+				continue
+			}
+			step := g.GetVertexAttribute(v)
+			if step.isLocal() {
+				done <- stepResult{v, step.run()}
+				running++
+				continue
+			}
+			err := b.Run(func() error {
+				done <- stepResult{v, step.run()}
+				return nil
+			})
+			if err != nil {
+				// The context was done while waiting for a free job
+				break
+			}
+			running++
+		}
+		if running == 0 {
+			break
+		}
+
+		res := <-done
+		running--
+		remaining--
+		if res.err != nil {
+			failures = append(failures, stepFailure{g.GetVertexAttribute(res.v), res.err})
+			skipDependents(res.v)
+			continue
+		}
+		for _, d := range dependents[res.v] {
+			pending[d]--
+			if pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	return failures, ctx.Err()
+}
@@ -0,0 +1,234 @@
+package builddb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+const databaseVersion = 1
+
+// FileStamp caches the hash of a file, the hash is computed again only
+// when the modification time or the size of the file changed.
+type FileStamp struct {
+	ModTime int64  `json:"mtime"`
+	Size    int64  `json:"size"`
+	Hash    string `json:"hash"`
+}
+
+// Record describes how a target was last built: the command used and
+// the hash of each of its inputs.
+type Record struct {
+	Command []string          `json:"command"`
+	Inputs  map[string]string `json:"inputs"`
+}
+
+// Database is the persistent build database of a project.
+type Database struct {
+	path    string
+	mutex   sync.Mutex
+	Version int                   `json:"version"`
+	Files   map[string]*FileStamp `json:"files"`
+	Targets map[string]*Record    `json:"targets"`
+}
+
+func New(path string) *Database {
+	return &Database{
+		path:    path,
+		Version: databaseVersion,
+		Files:   make(map[string]*FileStamp),
+		Targets: make(map[string]*Record),
+	}
+}
+
+// Open reads the database stored at path, an empty database is returned
+// if the file does not exist yet.
+func Open(path string) (*Database, error) {
+	db := New(path)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	} else if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, db)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read build database '%s':\n\t%s", path, err.Error())
+	}
+	if db.Version != databaseVersion {
+		return New(path), nil
+	}
+	if db.Files == nil {
+		db.Files = make(map[string]*FileStamp)
+	}
+	if db.Targets == nil {
+		db.Targets = make(map[string]*Record)
+	}
+	return db, nil
+}
+
+// Save writes the database on disk, the stamps of the files that are
+// not an input of any target are dropped.
+func (db *Database) Save() error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	used := make(map[string]bool)
+	for _, r := range db.Targets {
+		for f := range r.Inputs {
+			used[f] = true
+		}
+	}
+	for f := range db.Files {
+		if !used[f] {
+			delete(db.Files, f)
+		}
+	}
+	data, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(db.path), 0o755)
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(db.path, data, 0o644)
+}
+
+func hashFileContent(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile returns the hash of the content of file.
+func (db *Database) HashFile(file string) (string, error) {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return "", err
+	}
+	db.mutex.Lock()
+	stamp, ok := db.Files[file]
+	db.mutex.Unlock()
+	if ok && stamp.ModTime == stat.ModTime().UnixNano() && stamp.Size == stat.Size() {
+		return stamp.Hash, nil
+	}
+	hash, err := hashFileContent(file)
+	if err != nil {
+		return "", err
+	}
+	db.mutex.Lock()
+	db.Files[file] = &FileStamp{
+		ModTime: stat.ModTime().UnixNano(),
+		Size:    stat.Size(),
+		Hash:    hash,
+	}
+	db.mutex.Unlock()
+	return hash, nil
+}
+
+// HashFiles returns the hash of the content of each file.
+func (db *Database) HashFiles(files []string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	for _, file := range files {
+		hash, err := db.HashFile(file)
+		if err != nil {
+			return nil, err
+		}
+		hashes[file] = hash
+	}
+	return hashes, nil
+}
+
+func (db *Database) GetRecord(target string) *Record {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.Targets[target]
+}
+
+// SetRecord records that target has been built with command from
+// inputs, with the current content of inputs.
+func (db *Database) SetRecord(target string, command []string, inputs []string) error {
+	hashes, err := db.HashFiles(inputs)
+	if err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.Targets[target] = &Record{
+		Command: command,
+		Inputs:  hashes,
+	}
+	return nil
+}
+
+// RemoveRecord forgets how target was built, so that it is rebuilt the
+// next time.
+func (db *Database) RemoveRecord(target string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	delete(db.Targets, target)
+}
+
+const (
+	ReasonNoRecord      = "no previous build record"
+	ReasonFlagsChanged  = "flags changed"
+	ReasonInputsChanged = "set of inputs changed"
+)
+
+// OutdatedReason tells why target needs to be rebuilt with command from
+// inputs, an empty string is returned if it was last built with the same
+// command from inputs having the same content as they have now.
+func (db *Database) OutdatedReason(target string, command []string, inputs []string) (string, error) {
+	record := db.GetRecord(target)
+	if record == nil {
+		return ReasonNoRecord, nil
+	}
+	if !functional.ListEqual(record.Command, command) {
+		return ReasonFlagsChanged, nil
+	}
+	if len(record.Inputs) != len(inputs) {
+		return ReasonInputsChanged, nil
+	}
+	for _, input := range inputs {
+		hash, ok := record.Inputs[input]
+		if !ok {
+			return ReasonInputsChanged, nil
+		}
+		current, err := db.HashFile(input)
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("%s does not exist", input), nil
+		} else if err != nil {
+			return "", err
+		}
+		if current != hash {
+			return fmt.Sprintf("%s changed", input), nil
+		}
+	}
+	return "", nil
+}
+
+// IsUpToDate tells if target was last built with command from inputs
+// having the same content as they have now.
+func (db *Database) IsUpToDate(target string, command []string, inputs []string) (bool, error) {
+	reason, err := db.OutdatedReason(target, command, inputs)
+	if err != nil {
+		return false, err
+	}
+	return reason == "", nil
+}
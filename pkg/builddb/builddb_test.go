@@ -0,0 +1,117 @@
+package builddb_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/builddb"
+)
+
+func writeFile(t *testing.T, file, content string) {
+	if err := ioutil.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func isUpToDate(t *testing.T, db *builddb.Database, target string, command, inputs []string) bool {
+	upToDate, err := db.IsUpToDate(target, command, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return upToDate
+}
+
+func TestIsUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.cpp")
+	header := filepath.Join(dir, "main.hpp")
+	writeFile(t, source, "int main() {}")
+	writeFile(t, header, "#pragma once")
+	command := []string{"g++", "-c", source}
+
+	db := builddb.New(filepath.Join(dir, "db.json"))
+	if isUpToDate(t, db, "main.o", command, []string{source}) {
+		t.Fatal("target without record must not be up to date")
+	}
+	if err := db.SetRecord("main.o", command, []string{source}); err != nil {
+		t.Fatal(err)
+	}
+	if !isUpToDate(t, db, "main.o", command, []string{source}) {
+		t.Fatal("target must be up to date")
+	}
+	if isUpToDate(t, db, "main.o", []string{"g++", "-O2", "-c", source}, []string{source}) {
+		t.Fatal("target must be rebuilt when the command changes")
+	}
+	if isUpToDate(t, db, "main.o", command, []string{source, header}) {
+		t.Fatal("target must be rebuilt when its inputs change")
+	}
+
+	now := time.Now().Add(time.Minute)
+	if err := os.Chtimes(source, now, now); err != nil {
+		t.Fatal(err)
+	}
+	if !isUpToDate(t, db, "main.o", command, []string{source}) {
+		t.Fatal("target must be up to date when only the timestamp changes")
+	}
+
+	writeFile(t, source, "int main() { return 1; }")
+	if isUpToDate(t, db, "main.o", command, []string{source}) {
+		t.Fatal("target must be rebuilt when the content of a source changes")
+	}
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.cpp")
+	writeFile(t, source, "int main() {}")
+	command := []string{"g++", "-c", source}
+	dbPath := filepath.Join(dir, "build", "db.json")
+
+	db, err := builddb.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetRecord("main.o", command, []string{source}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = builddb.Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isUpToDate(t, db, "main.o", command, []string{source}) {
+		t.Fatal("record must be kept after reopening the database")
+	}
+}
+
+func TestOutdatedReason(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "main.cpp")
+	writeFile(t, source, "int main() {}")
+	command := []string{"g++", "-c", source}
+
+	db := builddb.New(filepath.Join(dir, "db.json"))
+	reason, err := db.OutdatedReason("main.o", command, []string{source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != builddb.ReasonNoRecord {
+		t.Fatalf("unexpected reason '%s'", reason)
+	}
+	if err := db.SetRecord("main.o", command, []string{source}); err != nil {
+		t.Fatal(err)
+	}
+	reason, err = db.OutdatedReason("main.o", []string{"g++", "-O3", "-c", source}, []string{source})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reason != builddb.ReasonFlagsChanged {
+		t.Fatalf("unexpected reason '%s'", reason)
+	}
+}
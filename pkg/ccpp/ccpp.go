@@ -10,14 +10,26 @@ import (
 var (
 	CPPSourceExts                  = []string{"cpp", "cc", "cxx", "C"}
 	CPPSourceExtsRe *regexp.Regexp = nil
+	CSourceExts                    = []string{"c"}
+	CSourceExtsRe   *regexp.Regexp = nil
 )
 
+func buildExtsRe(exts []string) *regexp.Regexp {
+	re := `^.*\.(`
+	re += strings.Join(exts, "|")
+	re += ")$"
+	return regexp.MustCompile(re)
+}
+
 func buildCPPSourceExtsRe() {
 	if CPPSourceExtsRe == nil {
-		re := `^.*\.(`
-		re += strings.Join(CPPSourceExts, "|")
-		re += ")$"
-		CPPSourceExtsRe = regexp.MustCompile(re)
+		CPPSourceExtsRe = buildExtsRe(CPPSourceExts)
+	}
+}
+
+func buildCSourceExtsRe() {
+	if CSourceExtsRe == nil {
+		CSourceExtsRe = buildExtsRe(CSourceExts)
 	}
 }
 
@@ -33,3 +45,21 @@ func IsCPPSourceFile(file string) bool {
 	buildCPPSourceExtsRe()
 	return CPPSourceExtsRe.MatchString(file)
 }
+
+func FilterCSourceFiles(files []string) []string {
+	buildCSourceExtsRe()
+	return functional.ListFilter(files,
+		func(s string) bool {
+			return CSourceExtsRe.MatchString(s)
+		})
+}
+
+func IsCSourceFile(file string) bool {
+	buildCSourceExtsRe()
+	return CSourceExtsRe.MatchString(file)
+}
+
+// IsSourceFile tells if file is either a C or a C++ source file.
+func IsSourceFile(file string) bool {
+	return IsCPPSourceFile(file) || IsCSourceFile(file)
+}
@@ -0,0 +1,23 @@
+package ccpp_test
+
+import (
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/ccpp"
+)
+
+func TestSourceFiles(t *testing.T) {
+	if !ccpp.IsCSourceFile("main.c") || ccpp.IsCPPSourceFile("main.c") {
+		t.Fatal("main.c should be a C source file")
+	}
+	if ccpp.IsCSourceFile("main.C") || !ccpp.IsCPPSourceFile("main.C") {
+		t.Fatal("main.C should be a C++ source file")
+	}
+	if ccpp.IsSourceFile("main.h") {
+		t.Fatal("main.h should not be a source file")
+	}
+	files := ccpp.FilterCSourceFiles([]string{"a.c", "b.cpp", "c.h", "d.c"})
+	if len(files) != 2 || files[0] != "a.c" || files[1] != "d.c" {
+		t.Fatalf("unexpected C source files %v", files)
+	}
+}
@@ -0,0 +1,53 @@
+package clang
+
+import (
+	"github.com/gueckmooh/bs/pkg/compiler/gcc"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+const (
+	ClangPPExec = "clang++"
+	ClangExec   = "clang"
+)
+
+// Flavor is the flavor of the clang compilers, which are GCC compatible
+// except for some of the dialect names.
+type Flavor struct {
+	gcc.GNUFlavor
+}
+
+func (f Flavor) CPPDialectOption(dialect int8) string {
+	switch dialect {
+	case project.DialectCPP0x:
+		return "-std=c++11"
+	case project.DialectCPP1y:
+		return "-std=c++14"
+	case project.DialectCPP1z:
+		return "-std=c++17"
+	case project.DialectCPP2a:
+		return "-std=c++20"
+	case project.DialectCPP23:
+		return "-std=c++2b"
+	case project.DialectCPPGNU0x:
+		return "-std=gnu++11"
+	case project.DialectCPPGNU1y:
+		return "-std=gnu++14"
+	case project.DialectCPPGNU1z:
+		return "-std=gnu++17"
+	case project.DialectCPPGNU2a:
+		return "-std=gnu++20"
+	case project.DialectCPPGNU23:
+		return "-std=gnu++2b"
+	}
+	return f.GNUFlavor.CPPDialectOption(dialect)
+}
+
+func (f Flavor) CDialectOption(dialect int8) string {
+	switch dialect {
+	case project.DialectC18:
+		return "-std=c17"
+	case project.DialectCGNU18:
+		return "-std=gnu17"
+	}
+	return f.GNUFlavor.CDialectOption(dialect)
+}
@@ -0,0 +1,24 @@
+package clang_test
+
+import (
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/compiler/clang"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func TestDialectOptions(t *testing.T) {
+	f := clang.Flavor{}
+	if o := f.CPPDialectOption(project.DialectCPP23); o != "-std=c++2b" {
+		t.Fatalf("unexpected C++23 option %s", o)
+	}
+	if o := f.CPPDialectOption(project.DialectCPP17); o != "-std=c++17" {
+		t.Fatalf("unexpected C++17 option %s", o)
+	}
+	if o := f.CDialectOption(project.DialectC18); o != "-std=c17" {
+		t.Fatalf("unexpected C18 option %s", o)
+	}
+	if o := f.PICOption(); o != "-fPIC" {
+		t.Fatalf("unexpected PIC option %s", o)
+	}
+}
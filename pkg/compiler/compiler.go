@@ -1,30 +1,58 @@
 package compiler
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gueckmooh/bs/pkg/compiler/clang"
 	"github.com/gueckmooh/bs/pkg/compiler/gcc"
 	"github.com/gueckmooh/bs/pkg/project"
 )
 
 type Compiler interface {
-	CompileFile(target, source string) error
-	LinkFiles(target string, sources ...string) error
-	GetFileDependencies(target, source string) (string, []string, error)
+	CompileCommand(target, source string) []string
+	CompileFile(ctx context.Context, target, source string) (string, error)
+	LinkCommand(target string, sources ...string) []string
+	LinkFiles(ctx context.Context, target string, sources ...string) (string, error)
+	GetFileDependencies(ctx context.Context, target, source string) ([]string, string, error)
+	DependencyFile(target string) string
+	ReadFileDependencies(target string) ([]string, error)
 }
 
 const (
 	targetExe int8 = iota
 	targetLib
+	targetStaticLib
 )
 
+// library is a library to link against, either given by its name or by
+// its file name.
+type library struct {
+	name   string
+	isFile bool
+}
+
 type compilerOption struct {
+	toolchain          string
+	toolchainPrefix    string
+	cCompiler          string
+	cxxCompiler        string
 	includeDirectories []string
 	libraryDirectories []string
-	libraries          []string
+	libraries          []library
+	runPaths           []string
 	forCPP             bool
 	targetKind         int8
 	cppDialect         int8
 	buildOptions       []string
+	cDialect           int8
+	cBuildOptions      []string
 	linkOptions        []string
+	optimization       project.Optimization
+	symbols            project.Switch
+	lto                project.Switch
+	sanitizers         []string
 }
 
 type CompilerOption func(*compilerOption)
@@ -43,7 +71,15 @@ func WithLibraryDirectory(libDir string) CompilerOption {
 
 func WithLibrary(lib string) CompilerOption {
 	return func(co *compilerOption) {
-		co.libraries = append(co.libraries, lib)
+		co.libraries = append(co.libraries, library{lib, false})
+	}
+}
+
+// WithLibraryFile links against the library file lib, found in the
+// library directories.
+func WithLibraryFile(lib string) CompilerOption {
+	return func(co *compilerOption) {
+		co.libraries = append(co.libraries, library{lib, true})
 	}
 }
 
@@ -55,10 +91,22 @@ func TargetLib(co *compilerOption) {
 	co.targetKind = targetLib
 }
 
+func TargetStaticLib(co *compilerOption) {
+	co.targetKind = targetStaticLib
+}
+
 func TargetExe(co *compilerOption) {
 	co.targetKind = targetExe
 }
 
+// WithRunPath adds path to the run path of the target, $ORIGIN being
+// the directory of the target.
+func WithRunPath(path string) CompilerOption {
+	return func(co *compilerOption) {
+		co.runPaths = append(co.runPaths, path)
+	}
+}
+
 func WithCPPDIalect(dialect int8) CompilerOption {
 	return func(co *compilerOption) {
 		co.cppDialect = dialect
@@ -71,24 +119,121 @@ func WithBuildOption(s string) CompilerOption {
 	}
 }
 
+func WithCDialect(dialect int8) CompilerOption {
+	return func(co *compilerOption) {
+		co.cDialect = dialect
+	}
+}
+
+func WithCBuildOption(s string) CompilerOption {
+	return func(co *compilerOption) {
+		co.cBuildOptions = append(co.cBuildOptions, s)
+	}
+}
+
 func WithLinkOption(s string) CompilerOption {
 	return func(co *compilerOption) {
 		co.linkOptions = append(co.linkOptions, s)
 	}
 }
 
-func NewCompiler(opts ...CompilerOption) Compiler {
+func WithOptimization(optimization project.Optimization) CompilerOption {
+	return func(co *compilerOption) {
+		co.optimization = optimization
+	}
+}
+
+func WithSymbols(symbols project.Switch) CompilerOption {
+	return func(co *compilerOption) {
+		co.symbols = symbols
+	}
+}
+
+func WithLTO(lto project.Switch) CompilerOption {
+	return func(co *compilerOption) {
+		co.lto = lto
+	}
+}
+
+func WithSanitizers(sanitizers ...string) CompilerOption {
+	return func(co *compilerOption) {
+		co.sanitizers = append(co.sanitizers, sanitizers...)
+	}
+}
+
+func WithToolchain(name string) CompilerOption {
+	return func(co *compilerOption) {
+		co.toolchain = name
+	}
+}
+
+// WithToolchainPrefix prefixes the executables of the toolchain with
+// prefix, as done by cross toolchains such as aarch64-linux-gnu-.
+func WithToolchainPrefix(prefix string) CompilerOption {
+	return func(co *compilerOption) {
+		co.toolchainPrefix = prefix
+	}
+}
+
+// WithCCompiler overrides the C compiler executable of the toolchain.
+func WithCCompiler(exe string) CompilerOption {
+	return func(co *compilerOption) {
+		co.cCompiler = exe
+	}
+}
+
+// WithCXXCompiler overrides the C++ compiler executable of the
+// toolchain.
+func WithCXXCompiler(exe string) CompilerOption {
+	return func(co *compilerOption) {
+		co.cxxCompiler = exe
+	}
+}
+
+func NewCompiler(opts ...CompilerOption) (Compiler, error) {
 	options := &compilerOption{
+		toolchain:  DefaultToolchain,
 		forCPP:     false,
 		cppDialect: project.DialectCPPUnknown,
+		cDialect:   project.DialectCUnknown,
 	}
 	for _, opt := range opts {
 		opt(options)
 	}
-	return options.newGCCCompiler()
+	newToolchainCompiler, ok := toolchains[options.toolchain]
+	if !ok {
+		return nil, fmt.Errorf("Unknown toolchain '%s', available toolchains are: %s",
+			options.toolchain, strings.Join(GetToolchainNames(), ", "))
+	}
+	return newToolchainCompiler(options), nil
 }
 
-func (co *compilerOption) newGCCCompiler() Compiler {
+// getExecutable returns the executable exe of the toolchain, unless it
+// is overridden.
+func (co *compilerOption) getExecutable(override, exe string) string {
+	if override != "" {
+		return override
+	}
+	return co.toolchainPrefix + exe
+}
+
+// getDebugLevel returns the level of the GCC compatible compilers
+// matching optimization.
+func getDebugLevel(optimization project.Optimization) gcc.DebugLevel {
+	switch optimization {
+	case project.OptimizeNone:
+		return gcc.DebugLevelO0
+	case project.OptimizeDebug:
+		return gcc.DebugLevelOg
+	case project.OptimizeSpeed:
+		return gcc.DebugLevelO2
+	case project.OptimizeSize:
+		return gcc.DebugLevelOs
+	}
+	return gcc.DebugLevelUnspecified
+}
+
+func (co *compilerOption) getGCCOptions() []gcc.GCCOption {
 	var opts []gcc.GCCOption
 	for _, v := range co.includeDirectories {
 		opts = append(opts, gcc.WithInclude(v))
@@ -97,11 +242,20 @@ func (co *compilerOption) newGCCCompiler() Compiler {
 		opts = append(opts, gcc.WithLibDir(v))
 	}
 	for _, v := range co.libraries {
-		opts = append(opts, gcc.WithLib(v))
+		if v.isFile {
+			opts = append(opts, gcc.WithLibFile(v.name))
+		} else {
+			opts = append(opts, gcc.WithLib(v.name))
+		}
+	}
+	for _, v := range co.runPaths {
+		opts = append(opts, gcc.WithRunPath(v))
 	}
 	switch co.targetKind {
 	case targetLib:
 		opts = append(opts, gcc.TargetLib)
+	case targetStaticLib:
+		opts = append(opts, gcc.TargetStaticLib)
 	}
 	if co.cppDialect != project.DialectCPPUnknown {
 		opts = append(opts, gcc.WithDialect(co.cppDialect))
@@ -109,8 +263,43 @@ func (co *compilerOption) newGCCCompiler() Compiler {
 	for _, v := range co.buildOptions {
 		opts = append(opts, gcc.WithBuildOption(v))
 	}
+	if co.cDialect != project.DialectCUnknown {
+		opts = append(opts, gcc.WithCDialect(co.cDialect))
+	}
+	for _, v := range co.cBuildOptions {
+		opts = append(opts, gcc.WithCBuildOption(v))
+	}
 	for _, v := range co.linkOptions {
 		opts = append(opts, gcc.WithLinkOption(v))
 	}
-	return gcc.NewGPP(opts...)
+	opts = append(opts,
+		gcc.WithDebugLevel(getDebugLevel(co.optimization)),
+		gcc.WithSymbols(co.symbols),
+		gcc.WithLTO(co.lto),
+		gcc.WithSanitizers(co.sanitizers...))
+	opts = append(opts, gcc.WithAR(co.getExecutable("", gcc.ARExec)))
+	return opts
+}
+
+func (co *compilerOption) newGCCCompiler() Compiler {
+	opts := co.getGCCOptions()
+	opts = append(opts,
+		gcc.WithCC(co.getExecutable(co.cCompiler, gcc.GCCExec)),
+		gcc.WithCXX(co.getExecutable(co.cxxCompiler, gcc.GPPExec)))
+	if co.forCPP {
+		return gcc.NewGPP(opts...)
+	}
+	return gcc.NewGCC(opts...)
+}
+
+func (co *compilerOption) newClangCompiler() Compiler {
+	opts := co.getGCCOptions()
+	opts = append(opts,
+		gcc.WithFlavor(clang.Flavor{}),
+		gcc.WithCC(co.getExecutable(co.cCompiler, clang.ClangExec)),
+		gcc.WithCXX(co.getExecutable(co.cxxCompiler, clang.ClangPPExec)))
+	if co.forCPP {
+		return gcc.NewGPP(opts...)
+	}
+	return gcc.NewGCC(opts...)
 }
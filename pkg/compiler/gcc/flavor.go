@@ -0,0 +1,146 @@
+package gcc
+
+import (
+	"strings"
+
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+// GNUFlavor is the flavor of the GNU compiler collection.
+type GNUFlavor struct{}
+
+func (GNUFlavor) PICOption() string {
+	return "-fPIC"
+}
+
+func (GNUFlavor) SharedOption() string {
+	return "-shared"
+}
+
+func (GNUFlavor) RunPathOption(path string) string {
+	return "-Wl,-rpath," + path
+}
+
+func (GNUFlavor) DebugLevelOption(level DebugLevel) string {
+	switch level {
+	case DebugLevelO0:
+		return "-O0"
+	case DebugLevelO1:
+		return "-O1"
+	case DebugLevelO2:
+		return "-O2"
+	case DebugLevelO3:
+		return "-O3"
+	case DebugLevelOg:
+		return "-Og"
+	case DebugLevelOs:
+		return "-Os"
+	}
+	return ""
+}
+
+func (GNUFlavor) SymbolsOption(enabled bool) string {
+	if enabled {
+		return "-g"
+	}
+	return "-g0"
+}
+
+func (GNUFlavor) SanitizeOption(sanitizers []string) string {
+	return "-fsanitize=" + strings.Join(sanitizers, ",")
+}
+
+func (GNUFlavor) LTOOption(enabled bool) string {
+	if enabled {
+		return "-flto"
+	}
+	return "-fno-lto"
+}
+
+func (GNUFlavor) CDialectOption(dialect int8) string {
+	switch dialect {
+	case project.DialectC89:
+		return "-std=c89"
+	case project.DialectC90:
+		return "-std=c90"
+	case project.DialectC99:
+		return "-std=c99"
+	case project.DialectC11:
+		return "-std=c11"
+	case project.DialectC17:
+		return "-std=c17"
+	case project.DialectC18:
+		return "-std=c18"
+	case project.DialectC2x:
+		return "-std=c2x"
+	case project.DialectCGNU89:
+		return "-std=gnu89"
+	case project.DialectCGNU90:
+		return "-std=gnu90"
+	case project.DialectCGNU99:
+		return "-std=gnu99"
+	case project.DialectCGNU11:
+		return "-std=gnu11"
+	case project.DialectCGNU17:
+		return "-std=gnu17"
+	case project.DialectCGNU18:
+		return "-std=gnu18"
+	case project.DialectCGNU2x:
+		return "-std=gnu2x"
+	}
+	return ""
+}
+
+func (GNUFlavor) CPPDialectOption(dialect int8) string {
+	switch dialect {
+	case project.DialectCPP98:
+		return "-std=c++98"
+	case project.DialectCPP03:
+		return "-std=c++03"
+	case project.DialectCPP11:
+		return "-std=c++11"
+	case project.DialectCPP0x:
+		return "-std=c++0x"
+	case project.DialectCPP14:
+		return "-std=c++14"
+	case project.DialectCPP1y:
+		return "-std=c++1y"
+	case project.DialectCPP17:
+		return "-std=c++17"
+	case project.DialectCPP1z:
+		return "-std=c++1z"
+	case project.DialectCPP20:
+		return "-std=c++20"
+	case project.DialectCPP2a:
+		return "-std=c++2a"
+	case project.DialectCPP23:
+		return "-std=c++23"
+	case project.DialectCPP2b:
+		return "-std=c++2b"
+	case project.DialectCPPGNU98:
+		return "-std=gnu++98"
+	case project.DialectCPPGNU03:
+		return "-std=gnu++03"
+	case project.DialectCPPGNU11:
+		return "-std=gnu++11"
+	case project.DialectCPPGNU0x:
+		return "-std=gnu++0x"
+	case project.DialectCPPGNU14:
+		return "-std=gnu++14"
+	case project.DialectCPPGNU1y:
+		return "-std=gnu++1y"
+	case project.DialectCPPGNU17:
+		return "-std=gnu++17"
+	case project.DialectCPPGNU1z:
+		return "-std=gnu++1z"
+	case project.DialectCPPGNU20:
+		return "-std=gnu++20"
+	case project.DialectCPPGNU2a:
+		return "-std=gnu++2a"
+	case project.DialectCPPGNU23:
+		return "-std=gnu++23"
+	case project.DialectCPPGNU2b:
+		return "-std=gnu++2b"
+	}
+	return ""
+}
@@ -2,31 +2,40 @@ package gcc
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/alessio/shellescape"
-	"github.com/gueckmooh/bs/pkg/common/colors"
+	"github.com/gueckmooh/bs/pkg/ccpp"
+	"github.com/gueckmooh/bs/pkg/fsutil"
 	"github.com/gueckmooh/bs/pkg/functional"
 	log "github.com/gueckmooh/bs/pkg/logging"
 	"github.com/gueckmooh/bs/pkg/project"
 )
 
+// DebugLevel is the optimization level of the compiler, no option is
+// given when it is unspecified.
 type DebugLevel int8
 
 const (
-	DebugLevelO0 DebugLevel = iota
+	DebugLevelUnspecified DebugLevel = iota
+	DebugLevelO0
 	DebugLevelO1
 	DebugLevelO2
 	DebugLevelO3
 	DebugLevelOg
+	DebugLevelOs
 )
 
 const (
 	GPPExec = "g++"
 	GCCExec = "gcc"
+	ARExec  = "ar"
 )
 
 type TargetKind int8
@@ -34,26 +43,82 @@ type TargetKind int8
 const (
 	targetExe TargetKind = iota
 	targetLib
+	targetStaticLib
 )
 
+// Flavor gives the flags specific to a family of GCC compatible
+// compilers.
+type Flavor interface {
+	CPPDialectOption(dialect int8) string
+	CDialectOption(dialect int8) string
+	PICOption() string
+	SharedOption() string
+	RunPathOption(path string) string
+	DebugLevelOption(level DebugLevel) string
+	SymbolsOption(enabled bool) string
+	SanitizeOption(sanitizers []string) string
+	LTOOption(enabled bool) string
+}
+
+// GCC compiles C sources with the C compiler and C++ sources with the
+// C++ compiler, the target is linked with the C++ compiler when gpp is
+// set. The default compilers are gcc and g++ with the GNU flavor.
 type GCC struct {
-	gpp          bool
-	debugLevel   DebugLevel
-	includes     []string
-	libDirs      []string
-	libs         []string
-	targetKind   TargetKind
-	dialect      int8
-	buildOptions []string
-	linkOptions  []string
+	gpp           bool
+	cc            string
+	cxx           string
+	ar            string
+	flavor        Flavor
+	debugLevel    DebugLevel
+	symbols       project.Switch
+	lto           project.Switch
+	sanitizers    []string
+	includes      []string
+	libDirs       []string
+	libs          []string
+	runPaths      []string
+	targetKind    TargetKind
+	dialect       int8
+	buildOptions  []string
+	cDialect      int8
+	cBuildOptions []string
+	linkOptions   []string
 }
 
 type GCCOption func(*GCC)
 
+func WithCC(exe string) GCCOption {
+	return func(g *GCC) {
+		g.cc = exe
+	}
+}
+
+func WithCXX(exe string) GCCOption {
+	return func(g *GCC) {
+		g.cxx = exe
+	}
+}
+
+func WithAR(exe string) GCCOption {
+	return func(g *GCC) {
+		g.ar = exe
+	}
+}
+
+func WithFlavor(flavor Flavor) GCCOption {
+	return func(g *GCC) {
+		g.flavor = flavor
+	}
+}
+
 func TargetLib(g *GCC) {
 	g.targetKind = targetLib
 }
 
+func TargetStaticLib(g *GCC) {
+	g.targetKind = targetStaticLib
+}
+
 func WithInclude(include string) GCCOption {
 	return func(g *GCC) {
 		g.includes = append(g.includes, include)
@@ -72,6 +137,49 @@ func WithLib(lib string) GCCOption {
 	}
 }
 
+// WithLibFile links against the library file lib, which allows to
+// choose between the static and the shared version of a library.
+func WithLibFile(lib string) GCCOption {
+	return func(g *GCC) {
+		g.libs = append(g.libs, ":"+lib)
+	}
+}
+
+// WithRunPath adds path to the directories searched for shared libraries
+// when the target is loaded.
+func WithRunPath(path string) GCCOption {
+	return func(g *GCC) {
+		g.runPaths = append(g.runPaths, path)
+	}
+}
+
+func WithDebugLevel(level DebugLevel) GCCOption {
+	return func(g *GCC) {
+		g.debugLevel = level
+	}
+}
+
+func WithSymbols(symbols project.Switch) GCCOption {
+	return func(g *GCC) {
+		g.symbols = symbols
+	}
+}
+
+// WithSanitizers compiles and links the targets with the sanitizers.
+func WithSanitizers(sanitizers ...string) GCCOption {
+	return func(g *GCC) {
+		g.sanitizers = append(g.sanitizers, sanitizers...)
+	}
+}
+
+// WithLTO enables or disables link time optimization, the objects are
+// then optimized again when they are linked.
+func WithLTO(lto project.Switch) GCCOption {
+	return func(g *GCC) {
+		g.lto = lto
+	}
+}
+
 func WithDialect(dialect int8) GCCOption {
 	return func(g *GCC) {
 		g.dialect = dialect
@@ -84,19 +192,36 @@ func WithBuildOption(s string) GCCOption {
 	}
 }
 
+func WithCDialect(dialect int8) GCCOption {
+	return func(g *GCC) {
+		g.cDialect = dialect
+	}
+}
+
+func WithCBuildOption(s string) GCCOption {
+	return func(g *GCC) {
+		g.cBuildOptions = append(g.cBuildOptions, s)
+	}
+}
+
 func WithLinkOption(s string) GCCOption {
 	return func(g *GCC) {
 		g.linkOptions = append(g.linkOptions, s)
 	}
 }
 
-func NewGPP(opts ...GCCOption) *GCC {
+func newGCC(gpp bool, opts ...GCCOption) *GCC {
 	gcc := &GCC{
-		gpp:        true,
-		debugLevel: DebugLevelO0,
+		gpp:        gpp,
+		cc:         GCCExec,
+		cxx:        GPPExec,
+		ar:         ARExec,
+		flavor:     GNUFlavor{},
+		debugLevel: DebugLevelUnspecified,
 		includes:   []string{},
 		targetKind: targetExe,
 		dialect:    project.DialectCPPUnknown,
+		cDialect:   project.DialectCUnknown,
 	}
 	for _, opt := range opts {
 		opt(gcc)
@@ -105,71 +230,114 @@ func NewGPP(opts ...GCCOption) *GCC {
 	return gcc
 }
 
-func runCommand(cmd []string) (string, string, error) {
-	exe := exec.Command(cmd[0], cmd[1:]...)
+// NewGPP returns a compiler linking its targets with the C++ compiler.
+func NewGPP(opts ...GCCOption) *GCC {
+	return newGCC(true, opts...)
+}
+
+// NewGCC returns a compiler linking its targets with the C compiler.
+func NewGCC(opts ...GCCOption) *GCC {
+	return newGCC(false, opts...)
+}
+
+// runCommand runs cmd, which is killed if ctx is done before it exits.
+// The command logged is shown instead, as cmd writes temporary files.
+func runCommand(ctx context.Context, cmd, shown []string) (string, string, error) {
+	exe := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	var outb, errb bytes.Buffer
 	exe.Stdout = &outb
 	exe.Stderr = &errb
-	log.Log.Printf("%s\n", shellescape.QuoteCommand(cmd))
+	log.Log.Printf("%s\n", shellescape.QuoteCommand(shown))
 	err := exe.Run()
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		err = ctxErr
+	}
 	return outb.String(), errb.String(), err
 }
 
-func (gcc *GCC) CompileFile(target, source string) error {
-	var cmd []string
-	if gcc.gpp {
-		cmd = append(cmd, GPPExec)
-	} else {
-		cmd = append(cmd, GCCExec)
+// commitOutputs renames the temporary files the outputs were written in
+// to the outputs. If err is set, the outputs were not completely written
+// and all of them are removed, so that they are not mistaken for
+// complete ones.
+func commitOutputs(err error, outputs ...string) error {
+	for _, output := range outputs {
+		if err == nil {
+			err = os.Rename(fsutil.TemporaryFile(output), output)
+		}
 	}
-
-	dialectopt := gcc.getDialectOption()
-	if dialectopt != "" {
-		cmd = append(cmd, dialectopt)
+	if err != nil {
+		for _, output := range outputs {
+			os.Remove(fsutil.TemporaryFile(output))
+			os.Remove(output)
+		}
 	}
+	return err
+}
 
-	for _, v := range gcc.buildOptions {
-		cmd = append(cmd, v)
-	}
+// CompileCommand returns the command used to compile source into the
+// object file target.
+func (gcc *GCC) CompileCommand(target, source string) []string {
+	cmd := gcc.getCompileDriverCommand(source)
 
 	includesOpts := functional.ListMap(gcc.includes,
 		func(s string) string {
 			return "-I" + s
 		})
 
-	if gcc.targetKind == targetLib {
-		cmd = append(cmd, "-fPIC")
+	// Static libraries may be linked into shared libraries
+	if gcc.targetKind == targetLib || gcc.targetKind == targetStaticLib {
+		cmd = append(cmd, gcc.flavor.PICOption())
 	}
 
 	cmd = append(cmd, includesOpts...)
 
+	cmd = append(cmd, []string{"-MMD", "-MF", gcc.DependencyFile(target)}...)
+
 	cmd = append(cmd, "-c")
 
 	cmd = append(cmd, source)
 
 	cmd = append(cmd, []string{"-o", target}...)
 
-	fmt.Printf("Compiling %s%s%s\n", colors.StyleBold, source, colors.StyleReset)
-	_, errs, err := runCommand(cmd)
+	return cmd
+}
+
+// CompileFile compiles source into the object file target and returns
+// the diagnostics printed by the compiler. The object file and its
+// dependency file are only replaced once they are completely written.
+func (gcc *GCC) CompileFile(ctx context.Context, target, source string) (string, error) {
+	cmd := gcc.CompileCommand(fsutil.TemporaryFile(target), source)
+
+	_, errs, err := runCommand(ctx, cmd, gcc.CompileCommand(target, source))
+	err = commitOutputs(err, target, gcc.DependencyFile(target))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s", errs)
-		return fmt.Errorf("Error while compiling file %s\n\t%s", source, err.Error())
+		return errs, fmt.Errorf("Error while compiling file %s\n\t%w", source, err)
 	}
-	return nil
+	return errs, nil
 }
 
-func (gcc *GCC) LinkFiles(target string, sources ...string) error {
+// LinkCommand returns the command used to link the object files
+// sources into target.
+func (gcc *GCC) LinkCommand(target string, sources ...string) []string {
 	var cmd []string
+	if gcc.targetKind == targetStaticLib {
+		cmd = append(cmd, []string{gcc.ar, "rcs", target}...)
+		cmd = append(cmd, sources...)
+		return cmd
+	}
+
 	if gcc.gpp {
-		cmd = append(cmd, GPPExec)
+		cmd = append(cmd, gcc.cxx)
 	} else {
-		cmd = append(cmd, GCCExec)
+		cmd = append(cmd, gcc.cc)
 	}
 
 	if gcc.targetKind == targetLib {
-		cmd = append(cmd, "-shared")
+		cmd = append(cmd, gcc.flavor.SharedOption())
 	}
 
+	cmd = append(cmd, gcc.getCodeGenerationOptions()...)
+
 	cmd = append(cmd, sources...)
 
 	cmd = append(cmd, []string{"-o", target}...)
@@ -186,42 +354,50 @@ func (gcc *GCC) LinkFiles(target string, sources ...string) error {
 	cmd = append(cmd, libDirOpts...)
 	cmd = append(cmd, libOpts...)
 
+	for _, v := range gcc.runPaths {
+		cmd = append(cmd, gcc.flavor.RunPathOption(v))
+	}
+
 	for _, v := range gcc.linkOptions {
 		cmd = append(cmd, v)
 	}
 
-	fmt.Printf("Linking %s%s%s\n", colors.StyleBold, target, colors.StyleReset)
-	_, errs, err := runCommand(cmd)
+	return cmd
+}
+
+// LinkFiles links the object files sources into target and returns the
+// diagnostics printed by the linker. The target is only replaced once it
+// is completely written.
+func (gcc *GCC) LinkFiles(ctx context.Context, target string, sources ...string) (string, error) {
+	tmp := fsutil.TemporaryFile(target)
+	cmd := gcc.LinkCommand(tmp, sources...)
+
+	if gcc.targetKind == targetStaticLib {
+		// ar would keep the objects that are not part of the library
+		// anymore
+		if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	_, errs, err := runCommand(ctx, cmd, gcc.LinkCommand(target, sources...))
+	err = commitOutputs(err, target)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s", errs)
-		return fmt.Errorf("Error while linking file %s\n\t%s", target, err.Error())
+		return errs, fmt.Errorf("Error while linking file %s\n\t%w", target, err)
 	}
-	return nil
+	return errs, nil
 }
 
-func (gcc *GCC) GetFileDependencies(target, source string) (string, []string, error) {
-	var cmd []string
-	if gcc.gpp {
-		cmd = append(cmd, GPPExec)
-	} else {
-		cmd = append(cmd, GCCExec)
-	}
+// GetFileDependencies scans the files the object file target is built
+// from and returns them along with the diagnostics printed by the
+// compiler.
+func (gcc *GCC) GetFileDependencies(ctx context.Context, target, source string) ([]string, string, error) {
+	cmd := gcc.getCompileDriverCommand(source)
 
 	includesOpts := functional.ListMap(gcc.includes,
 		func(s string) string {
 			return "-I" + s
 		})
 
-	// @todo add function that returns the options
-	dialectopt := gcc.getDialectOption()
-	if dialectopt != "" {
-		cmd = append(cmd, dialectopt)
-	}
-
-	for _, v := range gcc.buildOptions {
-		cmd = append(cmd, v)
-	}
-
 	cmd = append(cmd, includesOpts...)
 
 	cmd = append(cmd, "-MM")
@@ -230,12 +406,32 @@ func (gcc *GCC) GetFileDependencies(target, source string) (string, []string, er
 
 	cmd = append(cmd, []string{"-MT", target}...)
 
-	outs, errs, err := runCommand(cmd)
+	outs, errs, err := runCommand(ctx, cmd, cmd)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s", errs)
-		return "", nil, fmt.Errorf("Error while compiling file %s\n\t%s", source, err.Error())
+		return nil, errs, fmt.Errorf("Error while compiling file %s\n\t%w", source, err)
 	}
-	return ParseMOutput(outs)
+	_, sources, err := ParseMOutput(outs)
+	return sources, errs, err
+}
+
+// DependencyFile returns the file in which the dependencies of the
+// object file target are written when it is compiled.
+func (gcc *GCC) DependencyFile(target string) string {
+	return strings.TrimSuffix(target, filepath.Ext(target)) + ".d"
+}
+
+// ReadFileDependencies returns the files the object file target was
+// built from the last time it was compiled.
+func (gcc *GCC) ReadFileDependencies(target string) ([]string, error) {
+	content, err := ioutil.ReadFile(gcc.DependencyFile(target))
+	if err != nil {
+		return nil, err
+	}
+	_, sources, err := ParseMOutput(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
 }
 
 func ParseMOutput(o string) (string, []string, error) {
@@ -252,58 +448,49 @@ func ParseMOutput(o string) (string, []string, error) {
 	return target, sources, nil
 }
 
-func (g *GCC) getDialectOption() string {
-	if g.gpp {
-		switch g.dialect {
-		case project.DialectCPP98:
-			return "-std=c++98"
-		case project.DialectCPP03:
-			return "-std=c++03"
-		case project.DialectCPP11:
-			return "-std=c++11"
-		case project.DialectCPP0x:
-			return "-std=c++0x"
-		case project.DialectCPP14:
-			return "-std=c++14"
-		case project.DialectCPP1y:
-			return "-std=c++1y"
-		case project.DialectCPP17:
-			return "-std=c++17"
-		case project.DialectCPP1z:
-			return "-std=c++1z"
-		case project.DialectCPP20:
-			return "-std=c++20"
-		case project.DialectCPP2a:
-			return "-std=c++2a"
-		case project.DialectCPP23:
-			return "-std=c++23"
-		case project.DialectCPP2b:
-			return "-std=c++2b"
-		case project.DialectCPPGNU98:
-			return "-std=gnu++98"
-		case project.DialectCPPGNU03:
-			return "-std=gnu++03"
-		case project.DialectCPPGNU11:
-			return "-std=gnu++11"
-		case project.DialectCPPGNU0x:
-			return "-std=gnu++0x"
-		case project.DialectCPPGNU14:
-			return "-std=gnu++14"
-		case project.DialectCPPGNU1y:
-			return "-std=gnu++1y"
-		case project.DialectCPPGNU17:
-			return "-std=gnu++17"
-		case project.DialectCPPGNU1z:
-			return "-std=gnu++1z"
-		case project.DialectCPPGNU20:
-			return "-std=gnu++20"
-		case project.DialectCPPGNU2a:
-			return "-std=gnu++2a"
-		case project.DialectCPPGNU23:
-			return "-std=gnu++23"
-		case project.DialectCPPGNU2b:
-			return "-std=gnu++2b"
-		}
+// getCompileDriverCommand returns the driver used to compile source
+// along with the options of the language of source.
+func (gcc *GCC) getCompileDriverCommand(source string) []string {
+	var cmd []string
+	var dialectopt string
+	var buildOptions []string
+	if ccpp.IsCSourceFile(source) {
+		cmd = append(cmd, gcc.cc)
+		dialectopt = gcc.flavor.CDialectOption(gcc.cDialect)
+		buildOptions = gcc.cBuildOptions
+	} else {
+		cmd = append(cmd, gcc.cxx)
+		dialectopt = gcc.flavor.CPPDialectOption(gcc.dialect)
+		buildOptions = gcc.buildOptions
+	}
+
+	if dialectopt != "" {
+		cmd = append(cmd, dialectopt)
+	}
+
+	cmd = append(cmd, gcc.getCodeGenerationOptions()...)
+
+	cmd = append(cmd, buildOptions...)
+
+	return cmd
+}
+
+// getCodeGenerationOptions returns the optimization, debug symbols,
+// sanitizers and link time optimization options, which are given both
+// when compiling and when linking.
+func (gcc *GCC) getCodeGenerationOptions() []string {
+	var opts []string
+	if gcc.debugLevel != DebugLevelUnspecified {
+		opts = append(opts, gcc.flavor.DebugLevelOption(gcc.debugLevel))
+	}
+	if gcc.symbols != project.SwitchUnspecified {
+		opts = append(opts, gcc.flavor.SymbolsOption(gcc.symbols == project.SwitchOn))
+	}
+	if len(gcc.sanitizers) > 0 {
+		opts = append(opts, gcc.flavor.SanitizeOption(gcc.sanitizers))
+	}
+	if gcc.lto != project.SwitchUnspecified {
+		opts = append(opts, gcc.flavor.LTOOption(gcc.lto == project.SwitchOn))
 	}
-	return ""
+	return opts
 }
@@ -1,6 +1,8 @@
 package gcc_test
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/gueckmooh/bs/pkg/compiler/gcc"
@@ -21,3 +23,26 @@ func TestParseM(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestReadFileDependencies(t *testing.T) {
+	dir := t.TempDir()
+	g := gcc.NewGPP()
+	target := filepath.Join(dir, "hello.o")
+	if g.DependencyFile(target) != filepath.Join(dir, "hello.d") {
+		t.Fatalf("unexpected dependency file %s", g.DependencyFile(target))
+	}
+	content := target + ": hello.cpp \\\n hello.hpp\n"
+	if err := ioutil.WriteFile(g.DependencyFile(target), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := g.ReadFileDependencies(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || sources[0] != "hello.cpp" || sources[1] != "hello.hpp" {
+		t.Fatalf("unexpected dependencies %v", sources)
+	}
+	if _, err := g.ReadFileDependencies(filepath.Join(dir, "other.o")); err == nil {
+		t.Fatal("expected an error for a missing dependency file")
+	}
+}
@@ -0,0 +1,57 @@
+package compiler
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const DefaultToolchain = "gcc"
+
+// toolchains maps the name of each known toolchain to the function
+// creating its compilers.
+var toolchains = map[string]func(co *compilerOption) Compiler{
+	"gcc":   (*compilerOption).newGCCCompiler,
+	"clang": (*compilerOption).newClangCompiler,
+}
+
+func GetToolchainNames() []string {
+	var names []string
+	for name := range toolchains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func IsToolchain(name string) bool {
+	_, ok := toolchains[name]
+	return ok
+}
+
+// ToolchainFromExecutable guesses the toolchain a compiler executable
+// such as aarch64-linux-gnu-g++ or clang++-14 belongs to, an empty
+// string is returned if it is not known.
+func ToolchainFromExecutable(exe string) string {
+	base := filepath.Base(exe)
+	switch {
+	case strings.Contains(base, "clang"):
+		return "clang"
+	case strings.Contains(base, "g++"), strings.Contains(base, "gcc"):
+		return "gcc"
+	}
+	return ""
+}
+
+// ToolchainPrefixFromExecutable returns the cross prefix of a compiler
+// executable, such as aarch64-linux-gnu- for aarch64-linux-gnu-g++, an
+// empty string is returned if it has none.
+func ToolchainPrefixFromExecutable(exe string) string {
+	base := filepath.Base(exe)
+	for _, name := range []string{"g++", "gcc", "clang++", "clang"} {
+		if i := strings.LastIndex(base, "-"+name); i >= 0 {
+			return strings.TrimSuffix(exe, base) + base[:i+1]
+		}
+	}
+	return ""
+}
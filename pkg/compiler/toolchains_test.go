@@ -0,0 +1,93 @@
+package compiler_test
+
+import (
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/compiler"
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func TestToolchainFromExecutable(t *testing.T) {
+	for exe, toolchain := range map[string]string{
+		"g++":                       "gcc",
+		"/usr/bin/clang++-14":       "clang",
+		"aarch64-linux-gnu-gcc":     "gcc",
+		"aarch64-linux-gnu-clang++": "clang",
+		"cc":                        "",
+	} {
+		if got := compiler.ToolchainFromExecutable(exe); got != toolchain {
+			t.Errorf("toolchain of %s is %s, expected %s", exe, got, toolchain)
+		}
+	}
+}
+
+func TestToolchainPrefixFromExecutable(t *testing.T) {
+	for exe, prefix := range map[string]string{
+		"g++":                          "",
+		"clang++-14":                   "",
+		"aarch64-linux-gnu-g++":        "aarch64-linux-gnu-",
+		"/opt/x/bin/arm-none-eabi-gcc": "/opt/x/bin/arm-none-eabi-",
+		"x86_64-linux-gnu-gcc-12":      "x86_64-linux-gnu-",
+		"aarch64-linux-gnu-clang++":    "aarch64-linux-gnu-",
+	} {
+		if got := compiler.ToolchainPrefixFromExecutable(exe); got != prefix {
+			t.Errorf("prefix of %s is %s, expected %s", exe, got, prefix)
+		}
+	}
+}
+
+func TestNewCompiler(t *testing.T) {
+	if _, err := compiler.NewCompiler(compiler.WithToolchain("msvc")); err == nil {
+		t.Fatal("expected an error for an unknown toolchain")
+	}
+	c, err := compiler.NewCompiler(compiler.WithToolchain("clang"),
+		compiler.WithToolchainPrefix("aarch64-linux-gnu-"), compiler.ForCPP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := c.CompileCommand("main.o", "main.cpp")
+	if cmd[0] != "aarch64-linux-gnu-clang++" {
+		t.Fatalf("unexpected compile command %v", cmd)
+	}
+	cmd = c.CompileCommand("main.o", "main.c")
+	if cmd[0] != "aarch64-linux-gnu-clang" {
+		t.Fatalf("unexpected compile command %v", cmd)
+	}
+	c, err = compiler.NewCompiler(compiler.WithCXXCompiler("g++-12"), compiler.TargetStaticLib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd := c.CompileCommand("main.o", "main.cpp"); cmd[0] != "g++-12" {
+		t.Fatalf("unexpected compile command %v", cmd)
+	}
+	if cmd := c.LinkCommand("libmain.a", "main.o"); !functional.ListEqual(cmd, []string{"ar", "rcs", "libmain.a", "main.o"}) {
+		t.Fatalf("unexpected link command %v", cmd)
+	}
+}
+
+func TestCodeGenerationOptions(t *testing.T) {
+	c, err := compiler.NewCompiler(compiler.ForCPP,
+		compiler.WithOptimization(project.OptimizeSpeed),
+		compiler.WithSymbols(project.SwitchOn),
+		compiler.WithSanitizers("address", "undefined"),
+		compiler.WithLTO(project.SwitchOn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"-O2", "-g", "-fsanitize=address,undefined", "-flto"}
+	if cmd := c.CompileCommand("main.o", "main.cpp"); !functional.ListEqual(cmd[1:5], expected) {
+		t.Fatalf("unexpected compile command %v", cmd)
+	}
+	if cmd := c.LinkCommand("main", "main.o"); !functional.ListEqual(cmd[1:5], expected) {
+		t.Fatalf("unexpected link command %v", cmd)
+	}
+
+	c, err = compiler.NewCompiler(compiler.ForCPP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd := c.CompileCommand("main.o", "main.cpp"); cmd[1] != "-MMD" {
+		t.Fatalf("unexpected compile command without settings %v", cmd)
+	}
+}
@@ -0,0 +1,100 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Diagnostic is a message of the compiler about a location of a file,
+// the line and column being 0 when they are not given. Option is the
+// option enabling the diagnostic, like -Wunused-variable.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Option   string   `json:"option,omitempty"`
+}
+
+// GCC and Clang print their diagnostics as
+// file:line:column: severity: message [option]
+var diagnosticRegexp = regexp.MustCompile(
+	`^(.+?):(\d+):(?:(\d+):)? (fatal error|error|warning|note): (.*?)(?: \[(-W[^\]]+)\])?$`)
+
+// Parse returns the diagnostics in the output of GCC or Clang, the other
+// lines of the output, like the source excerpts, being ignored.
+func Parse(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		m := diagnosticRegexp.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		d := Diagnostic{
+			File:     m[1],
+			Severity: Severity(m[4]),
+			Message:  m[5],
+			Option:   m[6],
+		}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		if m[4] == "fatal error" {
+			d.Severity = SeverityError
+		}
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// Summary counts the errors and warnings of a build, along with the
+// files they are in.
+type Summary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Files    int `json:"files"`
+}
+
+// Summarize counts the errors and warnings in diags, a diagnostic of a
+// header reported while compiling several sources being counted once.
+func Summarize(diags []Diagnostic) Summary {
+	var s Summary
+	var files []string
+	for _, d := range functional.ListUniq(diags) {
+		switch d.Severity {
+		case SeverityError:
+			s.Errors++
+		case SeverityWarning:
+			s.Warnings++
+		default:
+			continue
+		}
+		files = append(files, d.File)
+	}
+	s.Files = len(functional.ListUniq(files))
+	return s
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%s, %s in %s", plural(s.Errors, "error"),
+		plural(s.Warnings, "warning"), plural(s.Files, "file"))
+}
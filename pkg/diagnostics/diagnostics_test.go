@@ -0,0 +1,121 @@
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/diagnostics"
+)
+
+const gccOutput = `src/main.cpp: In function 'int main()':
+src/main.cpp:4:9: warning: unused variable 'x' [-Wunused-variable]
+    4 |     int x;
+      |         ^
+src/main.cpp:5:12: error: 'nope' was not declared in this scope
+    5 |     return nope;
+      |            ^~~~
+In file included from src/main.cpp:1:
+include/a.hpp:2:1: note: declared here
+`
+
+const clangOutput = `src/b.cpp:3:10: fatal error: 'missing.h' file not found
+#include "missing.h"
+         ^~~~~~~~~~~
+include/a.hpp:7:5: warning: unused parameter 'y' [-Wunused-parameter]
+1 error generated.
+`
+
+func TestParse(t *testing.T) {
+	diags := diagnostics.Parse(gccOutput)
+	expected := []diagnostics.Diagnostic{
+		{File: "src/main.cpp", Line: 4, Column: 9, Severity: diagnostics.SeverityWarning,
+			Message: "unused variable 'x'", Option: "-Wunused-variable"},
+		{File: "src/main.cpp", Line: 5, Column: 12, Severity: diagnostics.SeverityError,
+			Message: "'nope' was not declared in this scope"},
+		{File: "include/a.hpp", Line: 2, Column: 1, Severity: diagnostics.SeverityNote,
+			Message: "declared here"},
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for i := range expected {
+		if diags[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], diags[i])
+		}
+	}
+
+	diags = diagnostics.Parse(clangOutput)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	if diags[0].Severity != diagnostics.SeverityError || diags[0].Message != "'missing.h' file not found" {
+		t.Errorf("unexpected fatal error %v", diags[0])
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	diags := append(diagnostics.Parse(gccOutput), diagnostics.Parse(clangOutput)...)
+	// The warnings of headers are reported for each source including them
+	diags = append(diags, diagnostics.Parse(clangOutput)...)
+	summary := diagnostics.Summarize(diags)
+	if s := summary.String(); s != "2 errors, 2 warnings in 3 files" {
+		t.Errorf("unexpected summary %q", s)
+	}
+	if s := diagnostics.Summarize(diags[:1]).String(); s != "0 errors, 1 warning in 1 file" {
+		t.Errorf("unexpected summary %q", s)
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+	data, err := diagnostics.SARIFReport("bs", diagnostics.Parse(gccOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("unexpected report %s", data)
+	}
+	run := report.Runs[0]
+	if run.Tool.Driver.Name != "bs" || len(run.Tool.Driver.Rules) != 1 ||
+		run.Tool.Driver.Rules[0].ID != "-Wunused-variable" {
+		t.Errorf("unexpected tool in report %s", data)
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(run.Results))
+	}
+	result := run.Results[1]
+	location := result.Locations[0].PhysicalLocation
+	if result.Level != "error" || location.ArtifactLocation.URI != "src/main.cpp" || location.Region.StartLine != 5 {
+		t.Errorf("unexpected result in report %s", data)
+	}
+}
@@ -0,0 +1,119 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// SARIFReport returns the diagnostics as a SARIF report of a single run
+// of the tool called name, the options enabling the diagnostics being its
+// rules.
+func SARIFReport(name string, diags []Diagnostic) ([]byte, error) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: name}},
+		Results: []sarifResult{},
+	}
+	var rules []string
+	for _, d := range functional.ListUniq(diags) {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: d.File}}
+		if d.Line > 0 {
+			location.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    d.Option,
+			Level:     string(d.Severity),
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+		if d.Option != "" {
+			rules = append(rules, d.Option)
+		}
+	}
+	for _, rule := range functional.ListUniq(rules) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: rule})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// WriteSARIFReport writes the SARIF report of the diagnostics in file.
+func WriteSARIFReport(file, name string, diags []Diagnostic) error {
+	data, err := SARIFReport(name, diags)
+	if err != nil {
+		return err
+	}
+	err = fsutil.WriteFileAtomic(file, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("Could not write SARIF report '%s':\n\t%s", file, err.Error())
+	}
+	return nil
+}
@@ -134,3 +134,25 @@ func CopyFile(from, to string) error {
 	}
 	return nil
 }
+
+// TemporaryFile returns the file in which file is written before being
+// renamed, so that it is never left half written. It is in the same
+// directory, with the same extension.
+func TemporaryFile(file string) string {
+	return filepath.Join(filepath.Dir(file), ".bs-tmp-"+filepath.Base(file))
+}
+
+// WriteFileAtomic writes data in file through a temporary file, so that
+// file is either left untouched or completely written.
+func WriteFileAtomic(file string, data []byte, perm os.FileMode) error {
+	tmp := TemporaryFile(file)
+	err := ioutil.WriteFile(tmp, data, perm)
+	if err == nil {
+		err = os.Rename(tmp, file)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
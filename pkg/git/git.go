@@ -3,8 +3,10 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 
 	"github.com/alessio/shellescape"
@@ -13,6 +15,9 @@ import (
 
 const (
 	GitBin = "git"
+	// CheckoutMarkerFile is written in the .git directory of the
+	// repositories cloned by bs, to find them when they are cleaned
+	CheckoutMarkerFile = "bs-checkout"
 )
 
 type GitRepository struct {
@@ -89,7 +94,7 @@ func (g *GitRepository) Clone() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return ioutil.WriteFile(filepath.Join(g.path, ".git", CheckoutMarkerFile), []byte(g.upstreamURL+"\n"), 0o644)
 }
 
 func (g *GitRepository) Checkout() error {
@@ -134,3 +139,40 @@ func (g *GitRepository) CloneAndCheckout() error {
 	}
 	return nil
 }
+
+// IsCheckout tells if path is a repository cloned by bs.
+func IsCheckout(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git", CheckoutMarkerFile))
+	return err == nil
+}
+
+// FindCheckouts returns the repositories cloned by bs in root, the
+// directories in skip are not searched.
+func FindCheckouts(root string, skip ...string) ([]string, error) {
+	var checkouts []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		for _, s := range skip {
+			if path == s {
+				return filepath.SkipDir
+			}
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if IsCheckout(path) {
+			checkouts = append(checkouts, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checkouts, nil
+}
@@ -2,6 +2,8 @@ package git_test
 
 import (
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
 	"testing"
 
@@ -116,3 +118,38 @@ func TestGitCloneAndCheckoutWithoutName(t *testing.T) {
 		t.Fatal("here must be a directory")
 	}
 }
+
+func TestFindCheckouts(t *testing.T) {
+	if _, err := exec.LookPath(git.GitBin); err != nil {
+		t.Skip("git is not available")
+	}
+	tmpdir := t.TempDir()
+	upstream := filepath.Join(tmpdir, "upstream")
+	if err := exec.Command(git.GitBin, "init", "-q", upstream).Run(); err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(tmpdir, "project")
+	gr := git.NewGitRepository(
+		git.WithUpstreamUrl(upstream),
+		git.WithPath(filepath.Join(project, "sources", "here")))
+	if err := gr.Clone(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(project, "sources", "mine", ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	checkouts, err := git.FindCheckouts(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkouts) != 1 || checkouts[0] != filepath.Join(project, "sources", "here") {
+		t.Errorf("unexpected checkouts %v", checkouts)
+	}
+	checkouts, err = git.FindCheckouts(project, filepath.Join(project, "sources"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkouts) != 0 {
+		t.Errorf("skipped directories must not be searched, got %v", checkouts)
+	}
+}
@@ -23,11 +23,22 @@ func NewLogger(writer io.Writer) *Logger {
 var (
 	Debug *Logger
 	Log   *Logger
+	// Info prints the progress messages which are always shown
+	Info *Logger
 )
 
 func init() {
 	Debug = NewLogger(os.Stdout)
 	Log = NewLogger(os.Stdout)
+	Info = NewLogger(os.Stdout)
+	Info.isActive = true
+}
+
+// SetOutput makes all the loggers print to writer.
+func SetOutput(writer io.Writer) {
+	Debug.writer = writer
+	Log.writer = writer
+	Info.writer = writer
 }
 
 func SetDebugLogging(v bool) {
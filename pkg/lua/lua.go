@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gueckmooh/bs/pkg/functional"
+	log "github.com/gueckmooh/bs/pkg/logging"
 	"github.com/gueckmooh/bs/pkg/lua/luabslib"
 	"github.com/gueckmooh/bs/pkg/lua/lualibs"
 	"github.com/gueckmooh/bs/pkg/project"
@@ -46,6 +48,17 @@ func luaSetBSVersion(L *lua.LState) int {
 	return 0
 }
 
+// luaPrint is the print function of lua, printing to the output of the
+// logs rather than to the standard output.
+func luaPrint(L *lua.LState) int {
+	var values []string
+	for i := 1; i <= L.GetTop(); i++ {
+		values = append(values, L.ToStringMeta(L.Get(i)).String())
+	}
+	log.Info.Printf("%s\n", strings.Join(values, "\t"))
+	return 0
+}
+
 func (C *LuaContext) LoadLuaBSLib() {
 	L := C.L
 	L.PreloadModule("project", luabslib.NewProjectLoader(&C.Project))
@@ -57,6 +70,7 @@ func (C *LuaContext) InitializeLuaState() {
 	L := C.L
 	luabslib.RegisterTypes(L)
 	L.SetGlobal("version", L.NewFunction(luaSetBSVersion))
+	L.SetGlobal("print", L.NewFunction(luaPrint))
 	C.LoadLuaBSLib()
 }
 
@@ -115,8 +129,20 @@ func (C *LuaContext) GetProject(root string) (*project.Project, error) {
 		return nil, err
 	}
 
+	project.ResolveIncludeDirectories(root, proj.BaseProfile, proj.Profiles, proj.Platforms)
+	for _, c := range components {
+		project.ResolveIncludeDirectories(c.Path, c.BaseProfile, c.Profiles, c.Platforms)
+	}
+
 	proj.Components = components
-	proj.Config = project.GetDefaultConfig(root)
+	proj.Config.ProjectRootDirectory = root
+	if dir := os.Getenv(project.BuildDirectoryEnvironmentVariable); dir != "" {
+		proj.Config.BuildRootDirectory = dir
+	}
+	err = proj.Config.Check()
+	if err != nil {
+		return nil, err
+	}
 
 	return proj, nil
 }
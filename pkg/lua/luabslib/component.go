@@ -15,13 +15,17 @@ var CurrentComponentFile string
 type Component struct {
 	FName             string
 	FType             string
+	FLinkage          string
 	FLanguages        []string
 	FSources          []string
 	FExportedHeaders  map[string]string
+	FInstall          map[string]string
 	FRequires         []string
+	FPackages         []string
 	FProfiles         map[string]*Profile
 	FBaseProfile      *Profile
 	FCPP              *CPPProfile
+	FC                *CProfile
 	FPlatforms        map[string]*Profile
 	FPrebuildActions  []*lua.LFunction
 	FPostbuildActions []*lua.LFunction
@@ -33,13 +37,17 @@ func NewComponent(name string) *Component {
 	c := &Component{
 		FName:             name,
 		FType:             "",
+		FLinkage:          "",
 		FLanguages:        []string{},
 		FSources:          []string{},
 		FExportedHeaders:  make(map[string]string),
+		FInstall:          make(map[string]string),
 		FRequires:         []string{},
+		FPackages:         []string{},
 		FProfiles:         make(map[string]*Profile),
 		FBaseProfile:      baseProfile,
 		FCPP:              baseProfile.FCPP,
+		FC:                baseProfile.FC,
 		FPlatforms:        make(map[string]*Profile),
 		FPrebuildActions:  []*lua.LFunction{},
 		FPostbuildActions: []*lua.LFunction{},
@@ -53,6 +61,10 @@ func (c *Component) Type(ty string) {
 	c.FType = ty
 }
 
+func (c *Component) Linkage(linkage string) {
+	c.FLinkage = linkage
+}
+
 func (c *Component) Languages(langs ...string) {
 	c.FLanguages = append(c.FLanguages, langs...)
 }
@@ -67,10 +79,22 @@ func (c *Component) ExportedHeaders(headers map[string]string) {
 	}
 }
 
+func (c *Component) Install(files map[string]string) {
+	for name, value := range files {
+		c.FInstall[name] = value
+	}
+}
+
 func (c *Component) Requires(req ...string) {
 	c.FRequires = append(c.FRequires, req...)
 }
 
+// RequiresPackage adds packages found with pkg-config, such as
+// "libpng >= 1.6", to the requirements of the component.
+func (c *Component) RequiresPackage(specs ...string) {
+	c.FPackages = append(c.FPackages, specs...)
+}
+
 func (c *Component) Profile(name string) *Profile {
 	if v, ok := c.FProfiles[name]; ok {
 		return v
@@ -84,6 +108,10 @@ func (c *Component) CPP() *CPPProfile {
 	return c.FCPP
 }
 
+func (c *Component) C() *CProfile {
+	return c.FC
+}
+
 func (c *Component) Platform(name string) *Profile {
 	if v, ok := c.FPlatforms[name]; ok {
 		return v
@@ -110,25 +138,29 @@ func ConvertLuaComponentToComponent(comp *Component) *project.Component {
 	for _, lang := range comp.FLanguages {
 		langIDs = append(langIDs, project.LanguageIDFromString(lang))
 	}
-	profiles := make(map[string]*project.Profile)
+	baseProfile, profiles := convertLuaProfilesToProfiles(comp.FBaseProfile, comp.FProfiles)
 	platforms := make(map[string]*project.Profile)
-	for name, profile := range comp.FProfiles {
-		profiles[name] = ConvertLuaProfileToProfile(profile)
-	}
 	for name, profile := range comp.FPlatforms {
 		platforms[name] = ConvertLuaProfileToProfile(profile)
 	}
+	linkage := project.LinkageFromString(comp.FLinkage)
+	if comp.FType == "static" && linkage == project.LinkageUnspecified {
+		linkage = project.LinkageStatic
+	}
 	ccomp := &project.Component{
 		Name:      comp.FName,
 		Languages: langIDs,
 		Sources: functional.ListMap(comp.FSources,
 			func(s string) project.FilesPattern { return project.FilesPattern(s) }),
 		Type:             project.ComponentTypeFromString(comp.FType),
+		Linkage:          linkage,
 		Path:             comp.FComponentPath,
 		ExportedHeaders:  comp.FExportedHeaders,
+		Install:          comp.FInstall,
 		Requires:         comp.FRequires,
+		Packages:         comp.FPackages,
 		Profiles:         profiles,
-		BaseProfile:      ConvertLuaProfileToProfile(comp.FBaseProfile),
+		BaseProfile:      baseProfile,
 		Platforms:        platforms,
 		PrebuildActions:  comp.FPrebuildActions,
 		PostbuildActions: comp.FPostbuildActions,
@@ -3,14 +3,25 @@ package luabslib
 //go:generate go run ./gen -i ./cppprofile.go -c CPPProfile -T ./gen/templates -P luabslib -o cppprofile_gen.go
 
 import (
+	"github.com/gueckmooh/bs/pkg/functional"
 	"github.com/gueckmooh/bs/pkg/project"
 	lua "github.com/yuin/gopher-lua"
 )
 
 type CPPProfile struct {
-	FDialect      string
-	FBuildOptions []string
-	FLinkOptions  []string
+	FDialect            string
+	FBuildOptions       []string
+	FLinkOptions        []string
+	FDefines            project.Defines
+	FUndefines          []string
+	FIncludeDirectories []string
+	FPublicBuildOptions []string
+	FPublicLinkOptions  []string
+	FPublicDefines      project.Defines
+	FOptimize           string
+	FSymbols            string
+	FLTO                string
+	FSanitize           []string
 }
 
 func (p *CPPProfile) Dialect(d string) {
@@ -25,6 +36,105 @@ func (p *CPPProfile) AddLinkOptions(bo ...string) {
 	p.FLinkOptions = append(p.FLinkOptions, bo...)
 }
 
+// AddDefines defines the macros, given as NAME or NAME=VALUE, when
+// compiling the component.
+func (p *CPPProfile) AddDefines(defines ...string) {
+	addDefines(p.FDefines, defines...)
+}
+
+// Defines defines the macros, given by name, when compiling the
+// component.
+func (p *CPPProfile) Defines(defines map[string]string) {
+	for name, value := range defines {
+		value := value
+		p.FDefines[name] = &value
+	}
+}
+
+// UndefineDefine removes the macros defined by the profiles this one is
+// merged into, such as the base profile for a named profile.
+func (p *CPPProfile) UndefineDefine(names ...string) {
+	p.FUndefines = append(p.FUndefines, names...)
+}
+
+// AddIncludeDirectories adds include directories, relative to the
+// component.
+func (p *CPPProfile) AddIncludeDirectories(dirs ...string) {
+	p.FIncludeDirectories = append(p.FIncludeDirectories, dirs...)
+}
+
+// AddPublicBuildOptions adds build options used to compile the component
+// and the components depending on it.
+func (p *CPPProfile) AddPublicBuildOptions(bo ...string) {
+	p.FPublicBuildOptions = append(p.FPublicBuildOptions, bo...)
+}
+
+// AddPublicLinkOptions adds link options used to link the component and
+// the components depending on it.
+func (p *CPPProfile) AddPublicLinkOptions(bo ...string) {
+	p.FPublicLinkOptions = append(p.FPublicLinkOptions, bo...)
+}
+
+// AddPublicDefines defines the macros when compiling the component and
+// the components depending on it.
+func (p *CPPProfile) AddPublicDefines(defines ...string) {
+	addDefines(p.FPublicDefines, defines...)
+}
+
+// PublicDefines defines the macros, given by name, when compiling the
+// component and the components depending on it.
+func (p *CPPProfile) PublicDefines(defines map[string]string) {
+	for name, value := range defines {
+		value := value
+		p.FPublicDefines[name] = &value
+	}
+}
+
+// Optimize sets the optimization of the sources, which is one of none,
+// debug, speed or size.
+func (p *CPPProfile) Optimize(optimization string) error {
+	if _, err := project.OptimizationFromString(optimization); err != nil {
+		return err
+	}
+	p.FOptimize = optimization
+	return nil
+}
+
+// Symbols tells if the debug symbols are generated, it is either on or
+// off.
+func (p *CPPProfile) Symbols(symbols string) error {
+	if _, err := project.SwitchFromString(symbols); err != nil {
+		return err
+	}
+	p.FSymbols = symbols
+	return nil
+}
+
+// Sanitize sets the sanitizers the sources are compiled and linked with,
+// "none" disables the ones of the profiles this one is merged into.
+func (p *CPPProfile) Sanitize(sanitizers ...string) error {
+	if err := project.CheckSanitizers(sanitizers); err != nil {
+		return err
+	}
+	p.FSanitize = sanitizers
+	return nil
+}
+
+// LTO enables or disables link time optimization.
+func (p *CPPProfile) LTO(enabled bool) {
+	p.FLTO = "off"
+	if enabled {
+		p.FLTO = "on"
+	}
+}
+
+func addDefines(m project.Defines, defines ...string) {
+	for _, define := range defines {
+		name, value := project.ParseDefine(define)
+		m[name] = value
+	}
+}
+
 func NewCPPProfileLoader(ret **CPPProfile) lua.LGFunction {
 	return __NewCPPProfileLoader(ret)
 }
@@ -35,9 +145,11 @@ func RegisterCPPProfileType(L *lua.LState) {
 
 func NewCPPProfile() *CPPProfile {
 	return &CPPProfile{
-		FDialect:      "",
-		FBuildOptions: []string{},
-		FLinkOptions:  []string{},
+		FDialect:       "",
+		FBuildOptions:  []string{},
+		FLinkOptions:   []string{},
+		FDefines:       project.Defines{},
+		FPublicDefines: project.Defines{},
 	}
 }
 
@@ -46,5 +158,28 @@ func ConvertLuaCPPProfileToCPPProfile(cpp *CPPProfile) *project.CPPProfile {
 	ccpp.SetDialectFromString(cpp.FDialect)
 	ccpp.BuildOptions = cpp.FBuildOptions
 	ccpp.LinkOptions = cpp.FLinkOptions
+	ccpp.Defines = cpp.FDefines.Clone()
+	ccpp.Undefines = cpp.FUndefines
+	ccpp.IncludeDirectories = cpp.FIncludeDirectories
+	ccpp.PublicBuildOptions = cpp.FPublicBuildOptions
+	ccpp.PublicLinkOptions = cpp.FPublicLinkOptions
+	ccpp.PublicDefines = cpp.FPublicDefines.Clone()
+	if cpp.FOptimize != "" {
+		ccpp.Optimize, _ = project.OptimizationFromString(cpp.FOptimize)
+	}
+	if cpp.FSymbols != "" {
+		ccpp.Symbols, _ = project.SwitchFromString(cpp.FSymbols)
+	}
+	if cpp.FLTO != "" {
+		ccpp.LTO, _ = project.SwitchFromString(cpp.FLTO)
+	}
+	if cpp.FSanitize != nil {
+		ccpp.Sanitizers = functional.ListFilter(cpp.FSanitize, func(s string) bool {
+			return s != project.SanitizeNone
+		})
+		if ccpp.Sanitizers == nil {
+			ccpp.Sanitizers = []string{}
+		}
+	}
 	return ccpp
 }
@@ -2,13 +2,19 @@ package luabslib_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/gueckmooh/bs/pkg/functional"
 	"github.com/gueckmooh/bs/pkg/lua/luabslib"
+	"github.com/gueckmooh/bs/pkg/project"
 	lua "github.com/yuin/gopher-lua"
 )
 
+func defineValue(v string) *string {
+	return &v
+}
+
 func TestLoaderRet(t *testing.T) {
 	L := lua.NewState()
 	defer L.Close()
@@ -31,3 +37,67 @@ p:AddBuildOptions "-Toto"
 		t.Fail()
 	}
 }
+
+func TestPublicOptions(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	var cppprofile *luabslib.CPPProfile
+	L.PreloadModule("cppprofile", luabslib.NewCPPProfileLoader(&cppprofile))
+	if err := L.DoString(`
+p = require "cppprofile"
+p:AddDefines {"PRIVATE", "EMPTY="}
+p:Defines { LEVEL = "3" }
+p:UndefineDefine "NDEBUG"
+p:AddIncludeDirectories "include/"
+p:AddPublicDefines {"USE_FOO", "FOO_LEVEL=2"}
+p:AddPublicBuildOptions "-fno-exceptions"
+p:AddPublicLinkOptions "-pthread"
+`); err != nil {
+		t.Fatal(err)
+	}
+	converted := luabslib.ConvertLuaCPPProfileToCPPProfile(cppprofile)
+	if !reflect.DeepEqual(converted.Defines, project.Defines{"PRIVATE": nil, "EMPTY": defineValue(""), "LEVEL": defineValue("3")}) {
+		t.Errorf("unexpected defines %v", converted.Defines)
+	}
+	if !functional.ListEqual(converted.Undefines, []string{"NDEBUG"}) {
+		t.Errorf("unexpected undefines %v", converted.Undefines)
+	}
+	if !functional.ListEqual(converted.IncludeDirectories, []string{"include/"}) {
+		t.Errorf("unexpected include directories %v", converted.IncludeDirectories)
+	}
+	if !reflect.DeepEqual(converted.PublicDefines, project.Defines{"USE_FOO": nil, "FOO_LEVEL": defineValue("2")}) {
+		t.Errorf("unexpected public defines %v", converted.PublicDefines)
+	}
+	if !functional.ListEqual(converted.PublicBuildOptions, []string{"-fno-exceptions"}) {
+		t.Errorf("unexpected public build options %v", converted.PublicBuildOptions)
+	}
+	if !functional.ListEqual(converted.PublicLinkOptions, []string{"-pthread"}) {
+		t.Errorf("unexpected public link options %v", converted.PublicLinkOptions)
+	}
+}
+
+func TestCodeGenerationSettings(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	var cppprofile *luabslib.CPPProfile
+	L.PreloadModule("cppprofile", luabslib.NewCPPProfileLoader(&cppprofile))
+	if err := L.DoString(`
+p = require "cppprofile"
+p:Optimize "size"
+p:Symbols "on"
+p:Sanitize {"address", "undefined"}
+p:LTO(true)
+`); err != nil {
+		t.Fatal(err)
+	}
+	converted := luabslib.ConvertLuaCPPProfileToCPPProfile(cppprofile)
+	if converted.Optimize != project.OptimizeSize || converted.Symbols != project.SwitchOn || converted.LTO != project.SwitchOn {
+		t.Errorf("unexpected settings %+v", converted)
+	}
+	if !functional.ListEqual(converted.Sanitizers, []string{"address", "undefined"}) {
+		t.Errorf("unexpected sanitizers %v", converted.Sanitizers)
+	}
+	if err := L.DoString(`p:Optimize "fast"`); err == nil {
+		t.Errorf("unknown optimization must be an error")
+	}
+}
@@ -0,0 +1,118 @@
+package luabslib
+
+//go:generate go run ./gen -i ./cprofile.go -c CProfile -T ./gen/templates -P luabslib -o cprofile_gen.go
+
+import (
+	"github.com/gueckmooh/bs/pkg/project"
+	lua "github.com/yuin/gopher-lua"
+)
+
+type CProfile struct {
+	FDialect            string
+	FBuildOptions       []string
+	FLinkOptions        []string
+	FDefines            project.Defines
+	FUndefines          []string
+	FIncludeDirectories []string
+	FPublicBuildOptions []string
+	FPublicLinkOptions  []string
+	FPublicDefines      project.Defines
+}
+
+func (p *CProfile) Dialect(d string) {
+	p.FDialect = d
+}
+
+func (p *CProfile) AddBuildOptions(bo ...string) {
+	p.FBuildOptions = append(p.FBuildOptions, bo...)
+}
+
+func (p *CProfile) AddLinkOptions(bo ...string) {
+	p.FLinkOptions = append(p.FLinkOptions, bo...)
+}
+
+// AddDefines defines the macros, given as NAME or NAME=VALUE, when
+// compiling the component.
+func (p *CProfile) AddDefines(defines ...string) {
+	addDefines(p.FDefines, defines...)
+}
+
+// Defines defines the macros, given by name, when compiling the
+// component.
+func (p *CProfile) Defines(defines map[string]string) {
+	for name, value := range defines {
+		value := value
+		p.FDefines[name] = &value
+	}
+}
+
+// UndefineDefine removes the macros defined by the profiles this one is
+// merged into, such as the base profile for a named profile.
+func (p *CProfile) UndefineDefine(names ...string) {
+	p.FUndefines = append(p.FUndefines, names...)
+}
+
+// AddIncludeDirectories adds include directories, relative to the
+// component.
+func (p *CProfile) AddIncludeDirectories(dirs ...string) {
+	p.FIncludeDirectories = append(p.FIncludeDirectories, dirs...)
+}
+
+// AddPublicBuildOptions adds build options used to compile the component
+// and the components depending on it.
+func (p *CProfile) AddPublicBuildOptions(bo ...string) {
+	p.FPublicBuildOptions = append(p.FPublicBuildOptions, bo...)
+}
+
+// AddPublicLinkOptions adds link options used to link the component and
+// the components depending on it.
+func (p *CProfile) AddPublicLinkOptions(bo ...string) {
+	p.FPublicLinkOptions = append(p.FPublicLinkOptions, bo...)
+}
+
+// AddPublicDefines defines the macros when compiling the component and
+// the components depending on it.
+func (p *CProfile) AddPublicDefines(defines ...string) {
+	addDefines(p.FPublicDefines, defines...)
+}
+
+// PublicDefines defines the macros, given by name, when compiling the
+// component and the components depending on it.
+func (p *CProfile) PublicDefines(defines map[string]string) {
+	for name, value := range defines {
+		value := value
+		p.FPublicDefines[name] = &value
+	}
+}
+
+func NewCProfileLoader(ret **CProfile) lua.LGFunction {
+	return __NewCProfileLoader(ret)
+}
+
+func RegisterCProfileType(L *lua.LState) {
+	__RegisterCProfileType(L)
+}
+
+func NewCProfile() *CProfile {
+	return &CProfile{
+		FDialect:       "",
+		FBuildOptions:  []string{},
+		FLinkOptions:   []string{},
+		FDefines:       project.Defines{},
+		FPublicDefines: project.Defines{},
+	}
+}
+
+func ConvertLuaCProfileToCProfile(c *CProfile) *project.CProfile {
+	cc := project.NewCProfile()
+	cc.SetDialectFromString(c.FDialect)
+	cc.BuildOptions = c.FBuildOptions
+	cc.LinkOptions = c.FLinkOptions
+	cc.Defines = c.FDefines.Clone()
+	cc.Undefines = c.FUndefines
+	cc.IncludeDirectories = c.FIncludeDirectories
+	cc.PublicBuildOptions = c.FPublicBuildOptions
+	cc.PublicLinkOptions = c.FPublicLinkOptions
+	cc.PublicDefines = c.FPublicDefines.Clone()
+	return cc
+}
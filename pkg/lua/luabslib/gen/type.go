@@ -24,6 +24,7 @@ type (
 	TNil    struct{}
 	TString struct{}
 	TInt    struct{}
+	TBool   struct{}
 	TError  struct{}
 	TCustom struct {
 		Name string
@@ -65,6 +66,7 @@ func (t *TUserData) GoString() string  { return "lua.LUserData" }
 func (t *TGFunction) GoString() string { return "lua.LGFunction" }
 func (t *TLFunction) GoString() string { return "lua.LFunction" }
 func (t *TInt) GoString() string       { return "int" }
+func (t *TBool) GoString() string      { return "bool" }
 func (t *TError) GoString() string     { return "error" }
 func (t *TMap) GoString() string {
 	return fmt.Sprintf("map[%s]%s", t.Key.GoString(), t.Val.GoString())
@@ -90,6 +92,7 @@ func (t *TUserData) LuaString() string  { return "lua.LTUserData" }
 func (t *TGFunction) LuaString() string { return "<nil>" }
 func (t *TLFunction) LuaString() string { return "<nil>" }
 func (t *TInt) LuaString() string       { return "lua.LTNumber" }
+func (t *TBool) LuaString() string      { return "lua.LTBool" }
 func (t *TError) LuaString() string     { return "<error>" }
 func (t *TArray) LuaString() string     { return "<nil>" }
 func (t *TMap) LuaString() string       { return "<nil>" }
@@ -106,6 +109,7 @@ func (t *TUserData) InsideType() Type  { return nil }
 func (t *TGFunction) InsideType() Type { return nil }
 func (t *TLFunction) InsideType() Type { return nil }
 func (t *TInt) InsideType() Type       { return nil }
+func (t *TBool) InsideType() Type      { return nil }
 func (t *TError) InsideType() Type     { return nil }
 func (t *TArray) InsideType() Type     { return t.X }
 func (t *TMap) InsideType() Type       { return t.Val }
@@ -122,6 +126,7 @@ func (t *TUserData) KeyType() Type  { panic("Cannot get key") }
 func (t *TGFunction) KeyType() Type { panic("Cannot get key") }
 func (t *TLFunction) KeyType() Type { panic("Cannot get key") }
 func (t *TInt) KeyType() Type       { panic("Cannot get key") }
+func (t *TBool) KeyType() Type      { panic("Cannot get key") }
 func (t *TError) KeyType() Type     { panic("Cannot get key") }
 func (t *TArray) KeyType() Type     { panic("Cannot get key") }
 func (t *TMap) KeyType() Type       { return t.Key }
@@ -157,6 +162,24 @@ func (t *TString) CheckFunction() Callable {
 	}
 }
 
+func (t *TBool) CheckFunction() Callable {
+	return &Method{
+		This: &TState{},
+		Function: Function{
+			Name: "CheckBool",
+			Type: &TFunction{
+				ReturnType: t,
+				Parameters: []*Field{
+					{
+						Name: "n",
+						Type: &TInt{},
+					},
+				},
+			},
+		},
+	}
+}
+
 func (t *TLFunction) CheckFunction() Callable {
 	return &Method{
 		This: &TState{},
@@ -240,6 +263,7 @@ func (t *TUserData) ToLuaType(v string) string  { return "" }
 func (t *TGFunction) ToLuaType(v string) string { return "" }
 func (t *TLFunction) ToLuaType(v string) string { return v }
 func (t *TInt) ToLuaType(v string) string       { return "lua.LNumber(" + v + ")" }
+func (t *TBool) ToLuaType(v string) string      { return "lua.LBool(" + v + ")" }
 func (t *TError) ToLuaType(v string) string     { panic("could not convert error") }
 func (t *TFunction) ToLuaType(v string) string  { return "" }
 func (t *TArray) ToLuaType(v string) string     { return "lua.LArray(" + v + ")" }
@@ -256,6 +280,7 @@ func (t *TUserData) ToGoType(v string) string  { return "<nil>" }
 func (t *TGFunction) ToGoType(v string) string { return "<nil>" }
 func (t *TLFunction) ToGoType(v string) string { return "<nil>" }
 func (t *TInt) ToGoType(v string) string       { return "<nil>" }
+func (t *TBool) ToGoType(v string) string      { return fmt.Sprintf("lua.LVAsBool(%s)", v) }
 func (t *TError) ToGoType(v string) string     { return "<nil>" }
 func (t *TFunction) ToGoType(v string) string  { return "<nil>" }
 func (t *TArray) ToGoType(v string) string     { return "<nil>" }
@@ -272,6 +297,7 @@ func (t *TUserData) IsContainer() bool  { return false }
 func (t *TGFunction) IsContainer() bool { return false }
 func (t *TLFunction) IsContainer() bool { return false }
 func (t *TInt) IsContainer() bool       { return false }
+func (t *TBool) IsContainer() bool      { return false }
 func (t *TError) IsContainer() bool     { return false }
 func (t *TFunction) IsContainer() bool  { return false }
 func (t *TArray) IsContainer() bool     { return true }
@@ -288,6 +314,7 @@ func (t *TUserData) IsMap() bool  { return false }
 func (t *TGFunction) IsMap() bool { return false }
 func (t *TLFunction) IsMap() bool { return false }
 func (t *TInt) IsMap() bool       { return false }
+func (t *TBool) IsMap() bool      { return false }
 func (t *TError) IsMap() bool     { return false }
 func (t *TFunction) IsMap() bool  { return false }
 func (t *TArray) IsMap() bool     { return false }
@@ -304,6 +331,7 @@ func (t *TUserData) NeedsEllipsis() bool  { return false }
 func (t *TGFunction) NeedsEllipsis() bool { return false }
 func (t *TLFunction) NeedsEllipsis() bool { return false }
 func (t *TInt) NeedsEllipsis() bool       { return false }
+func (t *TBool) NeedsEllipsis() bool      { return false }
 func (t *TError) NeedsEllipsis() bool     { return false }
 func (t *TFunction) NeedsEllipsis() bool  { return false }
 func (t *TArray) NeedsEllipsis() bool     { return false }
@@ -320,6 +348,7 @@ func (t *TUserData) IsError() bool  { return false }
 func (t *TGFunction) IsError() bool { return false }
 func (t *TLFunction) IsError() bool { return false }
 func (t *TInt) IsError() bool       { return false }
+func (t *TBool) IsError() bool      { return false }
 func (t *TError) IsError() bool     { return true }
 func (t *TFunction) IsError() bool  { return false }
 func (t *TArray) IsError() bool     { return false }
@@ -339,6 +368,8 @@ func newTypeFromName(name string) Type {
 	switch name {
 	case "string":
 		return &TString{}
+	case "bool":
+		return &TBool{}
 	case "error":
 		return &TError{}
 	default:
@@ -4,6 +4,7 @@ import lua "github.com/yuin/gopher-lua"
 
 func RegisterTypes(L *lua.LState) {
 	__RegisterCPPProfileType(L)
+	__RegisterCProfileType(L)
 	__RegisterComponentType(L)
 	__RegisterComponentsType(L)
 	__RegisterProfileType(L)
@@ -9,15 +9,21 @@ import (
 //go:generate go run ./gen -i ./profile.go -c Profile -T ./gen/templates -P luabslib -o profile_gen.go
 
 type Profile struct {
-	FName    string
-	FSources []string
-	FCPP     *CPPProfile
+	FName            string
+	FSources         []string
+	FCPP             *CPPProfile
+	FC               *CProfile
+	FLinkage         string
+	FToolchain       string
+	FToolchainPrefix string
+	FRPath           string
 }
 
 func NewProfile(name string) *Profile {
 	return &Profile{
 		FName: name,
 		FCPP:  NewCPPProfile(),
+		FC:    NewCProfile(),
 	}
 }
 
@@ -25,6 +31,26 @@ func (p *Profile) CPP() *CPPProfile {
 	return p.FCPP
 }
 
+func (p *Profile) C() *CProfile {
+	return p.FC
+}
+
+func (p *Profile) Linkage(linkage string) {
+	p.FLinkage = linkage
+}
+
+func (p *Profile) Toolchain(name string) {
+	p.FToolchain = name
+}
+
+func (p *Profile) ToolchainPrefix(prefix string) {
+	p.FToolchainPrefix = prefix
+}
+
+func (p *Profile) RPath(rpath string) {
+	p.FRPath = rpath
+}
+
 func NewProfileLoader(ret **Profile) lua.LGFunction {
 	return __NewProfileLoader(ret)
 }
@@ -36,7 +62,28 @@ func RegisterProfileType(L *lua.LState) {
 func ConvertLuaProfileToProfile(prof *Profile) *project.Profile {
 	pprof := project.NewProfile(prof.FName)
 	pprof.SetCPPProfile(ConvertLuaCPPProfileToCPPProfile(prof.FCPP))
+	pprof.SetCProfile(ConvertLuaCProfileToCProfile(prof.FC))
+	pprof.Linkage = project.LinkageFromString(prof.FLinkage)
+	pprof.Toolchain = prof.FToolchain
+	pprof.ToolchainPrefix = prof.FToolchainPrefix
+	pprof.RPath = prof.FRPath
 	pprof.Sources = functional.ListMap(prof.FSources,
 		func(s string) project.FilesPattern { return project.FilesPattern(s) })
 	return pprof
 }
+
+// convertLuaProfilesToProfiles converts the base profile and the named
+// profiles, which inherit from the base profile.
+func convertLuaProfilesToProfiles(base *Profile, profiles map[string]*Profile) (*project.Profile, map[string]*project.Profile) {
+	pbase := ConvertLuaProfileToProfile(base)
+	pprofiles := make(map[string]*project.Profile)
+	for name, profile := range profiles {
+		if profile == base {
+			pprofiles[name] = pbase
+			continue
+		}
+		pprofiles[name] = ConvertLuaProfileToProfile(profile)
+		pbase.AddSubProfile(pprofiles[name])
+	}
+	return pbase, pprofiles
+}
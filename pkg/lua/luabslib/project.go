@@ -18,10 +18,13 @@ type Project struct {
 	FDefaultTarget   string
 	FBaseProfile     *Profile
 	FCPP             *CPPProfile
+	FC               *CProfile
 	FProfiles        map[string]*Profile
 	FDefaultProfile  string
 	FPlatforms       map[string]*Profile
 	FDefaultPlatform string
+	FPkgConfigPath   []string
+	FConfig          *project.Config
 }
 
 func NewProject() *Project {
@@ -34,10 +37,13 @@ func NewProject() *Project {
 		FDefaultTarget:   "",
 		FBaseProfile:     baseProfile,
 		FCPP:             baseProfile.FCPP,
+		FC:               baseProfile.FC,
 		FProfiles:        make(map[string]*Profile),
 		FDefaultProfile:  "",
 		FPlatforms:       make(map[string]*Profile),
 		FDefaultPlatform: "",
+		FPkgConfigPath:   []string{},
+		FConfig:          project.GetDefaultConfig(""),
 	}
 	p.FProfiles["Default"] = baseProfile
 	return p
@@ -76,6 +82,18 @@ func (p *Project) CPP() *CPPProfile {
 	return p.FCPP
 }
 
+func (p *Project) C() *CProfile {
+	return p.FC
+}
+
+func (p *Project) Toolchain(name string) {
+	p.FBaseProfile.Toolchain(name)
+}
+
+func (p *Project) RPath(rpath string) {
+	p.FBaseProfile.RPath(rpath)
+}
+
 func (p *Project) DefaultProfile(name string) {
 	p.FDefaultProfile = name
 }
@@ -98,6 +116,42 @@ func (p *Project) DefaultPlatform(name string) {
 	p.FDefaultPlatform = name
 }
 
+// PkgConfigPath adds directories, relative to the project, where the .pc
+// files of the packages are searched.
+func (p *Project) PkgConfigPath(paths ...string) {
+	p.FPkgConfigPath = append(p.FPkgConfigPath, paths...)
+}
+
+// BuildDirectory sets the directory, relative to the project, where the
+// outputs of the builds are.
+func (p *Project) BuildDirectory(dir string) {
+	p.FConfig.BuildRootDirectory = dir
+}
+
+// BinDirectory sets the directory of the build directory where the
+// executables are.
+func (p *Project) BinDirectory(dir string) {
+	p.FConfig.BinDirectory = dir
+}
+
+func (p *Project) LibDirectory(dir string) {
+	p.FConfig.LibDirectory = dir
+}
+
+func (p *Project) TestDirectory(dir string) {
+	p.FConfig.TestDirectory = dir
+}
+
+func (p *Project) ObjDirectory(dir string) {
+	p.FConfig.ObjDirectory = dir
+}
+
+// HeadersDirectory sets the directory of the build directory where the
+// headers of the components are exported.
+func (p *Project) HeadersDirectory(dir string) {
+	p.FConfig.ExportHeadersDirectory = dir
+}
+
 func NewProjectLoader(ret **Project) lua.LGFunction {
 	return __NewProjectLoader(ret)
 }
@@ -107,11 +161,8 @@ func ConvertLuaProjectToProject(proj *Project) *project.Project {
 	for _, lang := range proj.FLanguages {
 		langIDs = append(langIDs, project.LanguageIDFromString(lang))
 	}
-	profiles := make(map[string]*project.Profile)
+	baseProfile, profiles := convertLuaProfilesToProfiles(proj.FBaseProfile, proj.FProfiles)
 	platforms := make(map[string]*project.Profile)
-	for name, profile := range proj.FProfiles {
-		profiles[name] = ConvertLuaProfileToProfile(profile)
-	}
 	for name, profile := range proj.FPlatforms {
 		platforms[name] = ConvertLuaProfileToProfile(profile)
 	}
@@ -122,11 +173,13 @@ func ConvertLuaProjectToProject(proj *Project) *project.Project {
 		Sources: functional.ListMap(proj.FSources,
 			func(s string) project.DirectoryPattern { return project.DirectoryPattern(s) }),
 		DefaultTarget:   proj.FDefaultTarget,
+		PkgConfigPath:   proj.FPkgConfigPath,
 		Profiles:        profiles,
-		BaseProfile:     ConvertLuaProfileToProfile(proj.FBaseProfile),
+		BaseProfile:     baseProfile,
 		DefaultProfile:  proj.FDefaultProfile,
 		Platforms:       platforms,
 		DefaultPlatform: proj.FDefaultPlatform,
+		Config:          proj.FConfig,
 	}
 	return pproj
 }
@@ -31,3 +31,28 @@ project:DefaultTarget "hello_exe"
 		t.Fail()
 	}
 }
+
+func TestProjectOutputDirectories(t *testing.T) {
+	L := lua.NewState()
+	defer L.Close()
+	luabslib.RegisterTypes(L)
+	var project *luabslib.Project
+	L.PreloadModule("project", luabslib.NewProjectLoader(&project))
+	if err := L.DoString(`
+project = require "project"
+
+project:BuildDirectory ".out"
+project:BinDirectory   "programs"
+project:LibDirectory   "lib64"
+`); err != nil {
+		t.Fatal(err)
+	}
+	config := luabslib.ConvertLuaProjectToProject(project).Config
+	if config.BuildRootDirectory != ".out" || config.BinDirectory != "programs" ||
+		config.LibDirectory != "lib64" || config.ObjDirectory != "obj" {
+		t.Errorf("unexpected config %+v", config)
+	}
+	if config.GetBinDirectory(true) != ".out/programs" {
+		t.Errorf("unexpected bin directory %s", config.GetBinDirectory(true))
+	}
+}
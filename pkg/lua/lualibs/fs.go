@@ -6,6 +6,7 @@ import (
 
 	"github.com/gueckmooh/bs/pkg/common/colors"
 	"github.com/gueckmooh/bs/pkg/fsutil"
+	log "github.com/gueckmooh/bs/pkg/logging"
 	lua "github.com/yuin/gopher-lua"
 )
 
@@ -17,7 +18,7 @@ var fslibFunctions = map[string]lua.LGFunction{
 func luaCopyFile(L *lua.LState) int {
 	from := L.ToString(1)
 	to := L.ToString(2)
-	fmt.Printf("Copying file %s%s%s to %s%s%s...\n",
+	log.Info.Printf("Copying file %s%s%s to %s%s%s...\n",
 		colors.StyleBold, from, colors.StyleReset,
 		colors.StyleBold, to, colors.StyleReset)
 	err := fsutil.CopyFile(from, to)
@@ -0,0 +1,137 @@
+package pkgconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/alessio/shellescape"
+	log "github.com/gueckmooh/bs/pkg/logging"
+)
+
+const (
+	PkgConfigBin = "pkg-config"
+)
+
+// Package is a package found by pkg-config, Spec is the requirement it
+// was found with, such as "libpng >= 1.6".
+type Package struct {
+	Spec   string
+	CFlags []string
+	Libs   []string
+}
+
+// PkgConfig queries pkg-config for the flags of the packages, the result
+// of each query is kept.
+type PkgConfig struct {
+	exe   string
+	paths []string
+	mutex sync.Mutex
+	cache map[string]*Package
+}
+
+type PkgConfigOption func(*PkgConfig)
+
+func WithExecutable(exe string) PkgConfigOption {
+	return func(p *PkgConfig) {
+		p.exe = exe
+	}
+}
+
+// WithSearchPath adds directories where the .pc files are searched before
+// the ones of PKG_CONFIG_PATH and the default ones.
+func WithSearchPath(paths ...string) PkgConfigOption {
+	return func(p *PkgConfig) {
+		p.paths = append(p.paths, paths...)
+	}
+}
+
+// NewPkgConfig returns a PkgConfig running the executable given by the
+// PKG_CONFIG environment variable, or pkg-config.
+func NewPkgConfig(opts ...PkgConfigOption) *PkgConfig {
+	p := &PkgConfig{
+		exe:   PkgConfigBin,
+		cache: make(map[string]*Package),
+	}
+	if exe := os.Getenv("PKG_CONFIG"); exe != "" {
+		p.exe = exe
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *PkgConfig) getEnvironment() []string {
+	env := os.Environ()
+	if len(p.paths) == 0 {
+		return env
+	}
+	paths := append([]string{}, p.paths...)
+	if old := os.Getenv("PKG_CONFIG_PATH"); old != "" {
+		paths = append(paths, old)
+	}
+	return append(env, "PKG_CONFIG_PATH="+strings.Join(paths, string(os.PathListSeparator)))
+}
+
+func (p *PkgConfig) run(args ...string) (string, error) {
+	cmd := append([]string{p.exe}, args...)
+	exe := exec.Command(cmd[0], cmd[1:]...)
+	exe.Env = p.getEnvironment()
+	var outb, errb bytes.Buffer
+	exe.Stdout = &outb
+	exe.Stderr = &errb
+	log.Log.Printf("%s\n", shellescape.QuoteCommand(cmd))
+	err := exe.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(errb.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s", msg)
+	} else if err != nil {
+		return "", fmt.Errorf("Could not run %s: %s", p.exe, err.Error())
+	}
+	return outb.String(), nil
+}
+
+func (p *PkgConfig) query(spec string) (*Package, error) {
+	_, err := p.run("--exists", "--print-errors", spec)
+	if err != nil {
+		return nil, err
+	}
+	cflags, err := p.run("--cflags", spec)
+	if err != nil {
+		return nil, err
+	}
+	libs, err := p.run("--libs", spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Package{
+		Spec:   spec,
+		CFlags: strings.Fields(cflags),
+		Libs:   strings.Fields(libs),
+	}, nil
+}
+
+// Query returns the package matching the requirement spec, an error
+// telling why is returned if there is none.
+func (p *PkgConfig) Query(spec string) (*Package, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if pkg, ok := p.cache[spec]; ok {
+		return pkg, nil
+	}
+	pkg, err := p.query(spec)
+	if err != nil {
+		return nil, fmt.Errorf("Package '%s' not found:\n\t%s", spec, err.Error())
+	}
+	p.cache[spec] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,61 @@
+package pkgconfig_test
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/pkgconfig"
+)
+
+const examplePC = `prefix=/opt/example
+libdir=${prefix}/lib
+includedir=${prefix}/include
+
+Name: example
+Description: An example package
+Version: 1.4.2
+Libs: -L${libdir} -lexample
+Cflags: -I${includedir} -DEXAMPLE
+`
+
+func newPkgConfig(t *testing.T) *pkgconfig.PkgConfig {
+	if _, err := exec.LookPath(pkgconfig.PkgConfigBin); err != nil {
+		t.Skip("pkg-config is not available")
+	}
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "example.pc"), []byte(examplePC), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return pkgconfig.NewPkgConfig(pkgconfig.WithExecutable(pkgconfig.PkgConfigBin),
+		pkgconfig.WithSearchPath(dir))
+}
+
+func TestQuery(t *testing.T) {
+	p := newPkgConfig(t)
+	pkg, err := p.Query("example >= 1.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !functional.ListEqual(pkg.CFlags, []string{"-I/opt/example/include", "-DEXAMPLE"}) {
+		t.Errorf("unexpected cflags %v", pkg.CFlags)
+	}
+	if !functional.ListEqual(pkg.Libs, []string{"-L/opt/example/lib", "-lexample"}) {
+		t.Errorf("unexpected libs %v", pkg.Libs)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	p := newPkgConfig(t)
+	_, err := p.Query("example >= 2.0")
+	if err == nil || !strings.Contains(err.Error(), "Package 'example >= 2.0' not found") {
+		t.Errorf("too old package must not be found, got %v", err)
+	}
+	_, err = p.Query("does-not-exist")
+	if err == nil {
+		t.Errorf("missing package must not be found")
+	}
+}
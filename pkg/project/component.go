@@ -12,17 +12,30 @@ const (
 	TypeExecutable ComponentType = iota
 	TypeLibrary
 	TypeHeaders
+	TypeTest
 	TypeUnknown
 )
 
+type Linkage int8
+
+const (
+	LinkageUnspecified Linkage = iota
+	LinkageShared
+	LinkageStatic
+	LinkageUnknown
+)
+
 type Component struct {
 	Name               string
 	Languages          []LanguageID
 	Sources            []FilesPattern
 	Type               ComponentType
+	Linkage            Linkage
 	Path               string
 	ExportedHeaders    map[string]string
+	Install            map[string]string
 	Requires           []string
+	Packages           []string
 	Profiles           map[string]*Profile
 	BaseProfile        *Profile
 	Platforms          map[string]*Profile
@@ -36,16 +49,53 @@ func ComponentTypeFromString(compTy string) ComponentType {
 	switch compTy {
 	case "executable":
 		return TypeExecutable
-	case "library":
+	case "library", "static":
 		return TypeLibrary
 	case "headers":
 		return TypeHeaders
+	case "test":
+		return TypeTest
 	}
 	return TypeUnknown
 }
 
-func (c *Component) GetTargetName() string {
+func LinkageFromString(linkage string) Linkage {
+	switch linkage {
+	case "":
+		return LinkageUnspecified
+	case "shared":
+		return LinkageShared
+	case "static":
+		return LinkageStatic
+	}
+	return LinkageUnknown
+}
+
+// ResolveLinkage returns how the component is built when the profile it
+// is built with has the linkage profileLinkage. The linkage of the
+// profile takes precedence over the one of the component, libraries are
+// shared by default.
+func (c *Component) ResolveLinkage(profileLinkage Linkage) Linkage {
+	if profileLinkage != LinkageUnspecified {
+		return profileLinkage
+	}
+	if c.Linkage != LinkageUnspecified {
+		return c.Linkage
+	}
+	return LinkageShared
+}
+
+// IsExecutable returns true if the target of the component is an
+// executable, tests are executables that are only built to be run by bs.
+func (c *Component) IsExecutable() bool {
+	return c.Type == TypeExecutable || c.Type == TypeTest
+}
+
+func (c *Component) GetTargetName(linkage Linkage) string {
 	if c.Type == TypeLibrary {
+		if linkage == LinkageStatic {
+			return fmt.Sprintf("lib%s.a", c.Name)
+		}
 		return fmt.Sprintf("lib%s.so", c.Name)
 	} else {
 		return c.Name
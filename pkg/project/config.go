@@ -1,22 +1,39 @@
 package project
 
-import "path/filepath"
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 type Config struct {
 	ProjectRootDirectory   string
 	BuildRootDirectory     string
 	BinDirectory           string
 	LibDirectory           string
+	TestDirectory          string
 	ObjDirectory           string
 	ExportHeadersDirectory string
+	// ConfigurationDirectory is the directory of the build root where
+	// the outputs of the selected profile and platform are
+	ConfigurationDirectory string
 }
 
 const (
 	DefaultBuildRootDirectory     = ".build"
 	DefaultBinDirectory           = "bin"
 	DefaultLibDirectory           = "lib"
+	DefaultTestDirectory          = "test"
 	DefaultObjDirectory           = "obj"
 	DefaultExportHeadersDirectory = "include"
+	DefaultBuildDatabaseFile      = "bs_db.json"
+	CompilationDatabaseFile       = "compile_commands.json"
+	DefaultConfigurationProfile   = "Default"
+	// BuildDirectoryEnvironmentVariable overrides the build root of the
+	// project when it is set
+	BuildDirectoryEnvironmentVariable = "BS_BUILD_DIRECTORY"
 )
 
 func GetDefaultConfig(root string) *Config {
@@ -25,47 +42,162 @@ func GetDefaultConfig(root string) *Config {
 		BuildRootDirectory:     DefaultBuildRootDirectory,
 		BinDirectory:           DefaultBinDirectory,
 		LibDirectory:           DefaultLibDirectory,
+		TestDirectory:          DefaultTestDirectory,
 		ObjDirectory:           DefaultObjDirectory,
 		ExportHeadersDirectory: DefaultExportHeadersDirectory,
 	}
 }
 
-func (c *Config) GetBinDirectory(rel bool) string {
-	if rel {
-		return filepath.Join(c.BuildRootDirectory, c.BinDirectory)
-	} else {
-		return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory, c.BinDirectory)
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Check returns an error if the directories of c can not hold the outputs
+// of a build, the build directory being removed by bs clean it must not
+// contain the project.
+func (c *Config) Check() error {
+	root, err := filepath.Abs(c.ProjectRootDirectory)
+	if err != nil {
+		return err
+	}
+	build, err := filepath.Abs(c.GetBuildDirectory(false))
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(build, root); err == nil && !isOutside(rel) {
+		return fmt.Errorf("The build directory '%s' contains the project", c.GetBuildDirectory(true))
+	}
+	for _, dir := range []string{c.BinDirectory, c.LibDirectory, c.TestDirectory,
+		c.ObjDirectory, c.ExportHeadersDirectory} {
+		clean := filepath.Clean(dir)
+		if dir == "" || filepath.IsAbs(dir) || clean == "." || isOutside(clean) {
+			return fmt.Errorf("The output directory '%s' must be a subdirectory of the build directory", dir)
+		}
+	}
+	return nil
+}
+
+// GetConfigurationName returns the name of the directory where the
+// outputs of the build with profile and platform are, the build with no
+// profile nor platform has its outputs directly in the build root. The
+// default profile is the one used when no profile is given, so it shares
+// these outputs.
+func GetConfigurationName(profile, platform string) string {
+	if (profile == "" || profile == DefaultConfigurationProfile) && platform == "" {
+		return ""
 	}
+	if profile == "" {
+		profile = DefaultConfigurationProfile
+	}
+	if platform == "" {
+		return profile
+	}
+	return profile + "-" + platform
+}
+
+// GetConfigurationNames returns the names of the configurations whose
+// outputs are in the build root, not including the build with no profile
+// nor platform.
+func (c *Config) GetConfigurationNames() ([]string, error) {
+	entries, err := ioutil.ReadDir(c.GetBuildRootDirectory(false))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		db := filepath.Join(c.GetBuildRootDirectory(false), entry.Name(), DefaultBuildDatabaseFile)
+		if _, err := os.Stat(db); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// SetConfiguration makes the outputs go to the directory of the build with
+// profile and platform.
+func (c *Config) SetConfiguration(profile, platform string) {
+	c.ConfigurationDirectory = GetConfigurationName(profile, platform)
+}
+
+// SetBuildDirectory makes the outputs go to dir, relative to the project
+// unless it is absolute.
+func (c *Config) SetBuildDirectory(dir string) {
+	c.BuildRootDirectory = dir
+	c.ConfigurationDirectory = ""
+}
+
+// getPath returns the path of elems in the build directory, relative to
+// the project if rel is set and the build root is relative.
+func (c *Config) getPath(rel bool, elems ...string) string {
+	path := filepath.Join(append([]string{c.BuildRootDirectory, c.ConfigurationDirectory}, elems...)...)
+	if rel || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.ProjectRootDirectory, path)
+}
+
+func (c *Config) GetBinDirectory(rel bool) string {
+	return c.getPath(rel, c.BinDirectory)
 }
 
 func (c *Config) GetLibDirectory(rel bool) string {
-	if rel {
-		return filepath.Join(c.BuildRootDirectory, c.LibDirectory)
-	} else {
-		return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory, c.LibDirectory)
+	return c.getPath(rel, c.LibDirectory)
+}
+
+func (c *Config) GetTestDirectory(rel bool) string {
+	return c.getPath(rel, c.TestDirectory)
+}
+
+// GetTargetDirectory returns the directory where the targets of the
+// components of type ty are built.
+func (c *Config) GetTargetDirectory(ty ComponentType, rel bool) string {
+	switch ty {
+	case TypeExecutable:
+		return c.GetBinDirectory(rel)
+	case TypeTest:
+		return c.GetTestDirectory(rel)
+	case TypeLibrary:
+		return c.GetLibDirectory(rel)
 	}
+	return c.GetBuildDirectory(rel)
 }
 
-func (c *Config) GetBuildDirectory(rel bool) string {
-	if rel {
+// GetBuildRootDirectory returns the directory containing the outputs of
+// all the configurations.
+func (c *Config) GetBuildRootDirectory(rel bool) string {
+	if rel || filepath.IsAbs(c.BuildRootDirectory) {
 		return c.BuildRootDirectory
-	} else {
-		return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory)
 	}
+	return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory)
+}
+
+// GetBuildDirectory returns the directory containing the outputs of the
+// selected configuration.
+func (c *Config) GetBuildDirectory(rel bool) string {
+	return c.getPath(rel)
 }
 
 func (c *Config) GetExportedHeadersDirectory(rel bool) string {
-	if rel {
-		return filepath.Join(c.BuildRootDirectory, c.ExportHeadersDirectory)
-	} else {
-		return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory, c.ExportHeadersDirectory)
-	}
+	return c.getPath(rel, c.ExportHeadersDirectory)
 }
 
 func (c *Config) GetObjDirectory(rel bool) string {
+	return c.getPath(rel, c.ObjDirectory)
+}
+
+func (c *Config) GetBuildDatabasePath(rel bool) string {
+	return c.getPath(rel, DefaultBuildDatabaseFile)
+}
+
+func (c *Config) GetCompilationDatabasePath(rel bool) string {
 	if rel {
-		return filepath.Join(c.BuildRootDirectory, c.ObjDirectory)
+		return CompilationDatabaseFile
 	} else {
-		return filepath.Join(c.ProjectRootDirectory, c.BuildRootDirectory, c.ObjDirectory)
+		return filepath.Join(c.ProjectRootDirectory, CompilationDatabaseFile)
 	}
 }
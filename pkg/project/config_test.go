@@ -0,0 +1,24 @@
+package project_test
+
+import (
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func TestGetConfigurationName(t *testing.T) {
+	for _, c := range []struct {
+		profile, platform, expected string
+	}{
+		{"", "", ""},
+		{"Default", "", ""},
+		{"Debug", "", "Debug"},
+		{"", "Linux64", "Default-Linux64"},
+		{"Default", "Linux64", "Default-Linux64"},
+		{"Debug", "Linux64", "Debug-Linux64"},
+	} {
+		if name := project.GetConfigurationName(c.profile, c.platform); name != c.expected {
+			t.Errorf("GetConfigurationName(%q, %q) = %q, expected %q", c.profile, c.platform, name, c.expected)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package project
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+)
 
 const (
 	DialectCPP98 int8 = iota
@@ -34,30 +39,93 @@ type CPPProfile struct {
 	Dialect      int8
 	BuildOptions []string
 	LinkOptions  []string
+	Defines      Defines
+	// Undefines are the macros removed from the defines of the profiles
+	// this one is merged into
+	Undefines []string
+	// IncludeDirectories are relative to the component, or to the
+	// project for the profiles of the project
+	IncludeDirectories []string
+	// The public options and defines are also used by the components
+	// depending on the component
+	PublicBuildOptions []string
+	PublicLinkOptions  []string
+	PublicDefines      Defines
+	// The code generation settings also apply to the C sources
+	Optimize Optimization
+	Symbols  Switch
+	LTO      Switch
+	// Sanitizers is nil when they are left to the profiles this one is
+	// merged into
+	Sanitizers []string
 }
 
 func NewCPPProfile() *CPPProfile {
 	return &CPPProfile{
-		Dialect: DialectCPPUnknown,
+		Dialect:       DialectCPPUnknown,
+		Defines:       Defines{},
+		PublicDefines: Defines{},
 	}
 }
 
 func (p *CPPProfile) Clone() *CPPProfile {
 	np := &CPPProfile{
-		Dialect:      p.Dialect,
-		BuildOptions: p.BuildOptions,
-		LinkOptions:  p.LinkOptions,
+		Dialect:            p.Dialect,
+		BuildOptions:       append([]string{}, p.BuildOptions...),
+		LinkOptions:        append([]string{}, p.LinkOptions...),
+		Defines:            p.Defines.Clone(),
+		Undefines:          append([]string{}, p.Undefines...),
+		IncludeDirectories: append([]string{}, p.IncludeDirectories...),
+		PublicBuildOptions: append([]string{}, p.PublicBuildOptions...),
+		PublicLinkOptions:  append([]string{}, p.PublicLinkOptions...),
+		PublicDefines:      p.PublicDefines.Clone(),
+		Optimize:           p.Optimize,
+		Symbols:            p.Symbols,
+		LTO:                p.LTO,
+		Sanitizers:         p.Sanitizers,
 	}
 	return np
 }
 
 func (p *CPPProfile) Merge(op *CPPProfile) (np *CPPProfile) {
 	np = p.Clone()
+	if op.Dialect != DialectCPPUnknown {
+		np.Dialect = op.Dialect
+	}
 	np.BuildOptions = append(np.BuildOptions, op.BuildOptions...)
 	np.LinkOptions = append(np.LinkOptions, op.LinkOptions...)
+	np.Defines = np.Defines.Merge(op.Defines, op.Undefines)
+	np.Undefines = append(np.Undefines, op.Undefines...)
+	np.IncludeDirectories = functional.ListUniq(append(np.IncludeDirectories, op.IncludeDirectories...))
+	np.PublicBuildOptions = append(np.PublicBuildOptions, op.PublicBuildOptions...)
+	np.PublicLinkOptions = append(np.PublicLinkOptions, op.PublicLinkOptions...)
+	np.PublicDefines = np.PublicDefines.Merge(op.PublicDefines, op.Undefines)
+	if op.Optimize != OptimizeUnspecified {
+		np.Optimize = op.Optimize
+	}
+	if op.Symbols != SwitchUnspecified {
+		np.Symbols = op.Symbols
+	}
+	if op.LTO != SwitchUnspecified {
+		np.LTO = op.LTO
+	}
+	if op.Sanitizers != nil {
+		np.Sanitizers = op.Sanitizers
+	}
 	return np
 }
 
+// resolveIncludeDirectories makes the relative include directories
+// relative to dir.
+func (p *CPPProfile) resolveIncludeDirectories(dir string) {
+	p.IncludeDirectories = functional.ListMap(p.IncludeDirectories, func(inc string) string {
+		if filepath.IsAbs(inc) {
+			return inc
+		}
+		return filepath.Join(dir, inc)
+	})
+}
+
 func (p *CPPProfile) SetDialectFromString(s string) error {
 	p.Dialect = cppDialectFromString(s)
 	if p.Dialect == DialectCPPUnknown {
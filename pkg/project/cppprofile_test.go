@@ -0,0 +1,50 @@
+package project_test
+
+import (
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func TestMergeCodeGenerationSettings(t *testing.T) {
+	base := project.NewCPPProfile()
+	base.Optimize = project.OptimizeSpeed
+	base.Symbols = project.SwitchOff
+	base.Sanitizers = []string{"address"}
+	debug := project.NewCPPProfile()
+	debug.Optimize = project.OptimizeDebug
+	debug.LTO = project.SwitchOn
+
+	merged := base.Merge(debug)
+	if merged.Optimize != project.OptimizeDebug || merged.Symbols != project.SwitchOff || merged.LTO != project.SwitchOn {
+		t.Errorf("unexpected merged settings %+v", merged)
+	}
+	if !functional.ListEqual(merged.Sanitizers, []string{"address"}) {
+		t.Errorf("unspecified sanitizers must be inherited, got %v", merged.Sanitizers)
+	}
+
+	noSanitizers := project.NewCPPProfile()
+	noSanitizers.Sanitizers = []string{}
+	if merged := base.Merge(noSanitizers); len(merged.Sanitizers) != 0 || merged.Optimize != project.OptimizeSpeed {
+		t.Errorf("sanitizers must be disabled, got %+v", merged)
+	}
+}
+
+func TestSettingsFromString(t *testing.T) {
+	if o, err := project.OptimizationFromString("size"); err != nil || o != project.OptimizeSize {
+		t.Errorf("unexpected optimization %v, %v", o, err)
+	}
+	if _, err := project.OptimizationFromString("fast"); err == nil {
+		t.Errorf("unknown optimization must be an error")
+	}
+	if _, err := project.SwitchFromString("yes"); err == nil {
+		t.Errorf("unknown switch must be an error")
+	}
+	if err := project.CheckSanitizers([]string{"address", "none"}); err != nil {
+		t.Error(err)
+	}
+	if err := project.CheckSanitizers([]string{"memory-leaks"}); err == nil {
+		t.Errorf("unknown sanitizer must be an error")
+	}
+}
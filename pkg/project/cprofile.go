@@ -0,0 +1,136 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+const (
+	DialectC89 int8 = iota
+	DialectC90
+	DialectC99
+	DialectC11
+	DialectC17
+	DialectC18
+	DialectC2x
+	DialectCGNU89
+	DialectCGNU90
+	DialectCGNU99
+	DialectCGNU11
+	DialectCGNU17
+	DialectCGNU18
+	DialectCGNU2x
+	DialectCUnknown
+)
+
+type CProfile struct {
+	Dialect      int8
+	BuildOptions []string
+	LinkOptions  []string
+	Defines      Defines
+	// Undefines are the macros removed from the defines of the profiles
+	// this one is merged into
+	Undefines []string
+	// IncludeDirectories are relative to the component, or to the
+	// project for the profiles of the project
+	IncludeDirectories []string
+	// The public options and defines are also used by the components
+	// depending on the component
+	PublicBuildOptions []string
+	PublicLinkOptions  []string
+	PublicDefines      Defines
+}
+
+func NewCProfile() *CProfile {
+	return &CProfile{
+		Dialect:       DialectCUnknown,
+		Defines:       Defines{},
+		PublicDefines: Defines{},
+	}
+}
+
+func (p *CProfile) Clone() *CProfile {
+	np := &CProfile{
+		Dialect:            p.Dialect,
+		BuildOptions:       append([]string{}, p.BuildOptions...),
+		LinkOptions:        append([]string{}, p.LinkOptions...),
+		Defines:            p.Defines.Clone(),
+		Undefines:          append([]string{}, p.Undefines...),
+		IncludeDirectories: append([]string{}, p.IncludeDirectories...),
+		PublicBuildOptions: append([]string{}, p.PublicBuildOptions...),
+		PublicLinkOptions:  append([]string{}, p.PublicLinkOptions...),
+		PublicDefines:      p.PublicDefines.Clone(),
+	}
+	return np
+}
+
+func (p *CProfile) Merge(op *CProfile) (np *CProfile) {
+	np = p.Clone()
+	if op.Dialect != DialectCUnknown {
+		np.Dialect = op.Dialect
+	}
+	np.BuildOptions = append(np.BuildOptions, op.BuildOptions...)
+	np.LinkOptions = append(np.LinkOptions, op.LinkOptions...)
+	np.Defines = np.Defines.Merge(op.Defines, op.Undefines)
+	np.Undefines = append(np.Undefines, op.Undefines...)
+	np.IncludeDirectories = functional.ListUniq(append(np.IncludeDirectories, op.IncludeDirectories...))
+	np.PublicBuildOptions = append(np.PublicBuildOptions, op.PublicBuildOptions...)
+	np.PublicLinkOptions = append(np.PublicLinkOptions, op.PublicLinkOptions...)
+	np.PublicDefines = np.PublicDefines.Merge(op.PublicDefines, op.Undefines)
+	return np
+}
+
+// resolveIncludeDirectories makes the relative include directories
+// relative to dir.
+func (p *CProfile) resolveIncludeDirectories(dir string) {
+	p.IncludeDirectories = functional.ListMap(p.IncludeDirectories, func(inc string) string {
+		if filepath.IsAbs(inc) {
+			return inc
+		}
+		return filepath.Join(dir, inc)
+	})
+}
+
+func (p *CProfile) SetDialectFromString(s string) error {
+	p.Dialect = cDialectFromString(s)
+	if p.Dialect == DialectCUnknown {
+		return fmt.Errorf("Unknown C dialect '%s'", s)
+	}
+	return nil
+}
+
+func cDialectFromString(s string) int8 {
+	switch s {
+	case "C89":
+		return DialectC89
+	case "C90":
+		return DialectC90
+	case "C99":
+		return DialectC99
+	case "C11":
+		return DialectC11
+	case "C17":
+		return DialectC17
+	case "C18":
+		return DialectC18
+	case "C2x":
+		return DialectC2x
+	case "GNU89":
+		return DialectCGNU89
+	case "GNU90":
+		return DialectCGNU90
+	case "GNU99":
+		return DialectCGNU99
+	case "GNU11":
+		return DialectCGNU11
+	case "GNU17":
+		return DialectCGNU17
+	case "GNU18":
+		return DialectCGNU18
+	case "GNU2x":
+		return DialectCGNU2x
+	}
+	return DialectCUnknown
+}
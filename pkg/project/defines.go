@@ -0,0 +1,51 @@
+package project
+
+import (
+	"sort"
+	"strings"
+)
+
+// Defines are preprocessor macros by name, a macro with a nil value is
+// defined without value while an empty value defines it as empty.
+type Defines map[string]*string
+
+// ParseDefine splits a macro given as NAME, NAME= or NAME=VALUE, the value
+// being nil for NAME.
+func ParseDefine(define string) (name string, value *string) {
+	name, v, found := strings.Cut(define, "=")
+	if found {
+		value = &v
+	}
+	return name, value
+}
+
+func (d Defines) Clone() Defines {
+	nd := make(Defines, len(d))
+	for name, value := range d {
+		nd[name] = value
+	}
+	return nd
+}
+
+// Merge returns the macros of d overridden by the ones of od, the macros
+// of undefines being removed from d first.
+func (d Defines) Merge(od Defines, undefines []string) Defines {
+	nd := d.Clone()
+	for _, name := range undefines {
+		delete(nd, name)
+	}
+	for name, value := range od {
+		nd[name] = value
+	}
+	return nd
+}
+
+// Names returns the names of the macros, sorted.
+func (d Defines) Names() []string {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package project_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/project"
+)
+
+func defineValue(v string) *string {
+	return &v
+}
+
+func TestParseDefine(t *testing.T) {
+	for define, expected := range map[string]struct {
+		name  string
+		value *string
+	}{
+		"NDEBUG":       {"NDEBUG", nil},
+		"EMPTY=":       {"EMPTY", defineValue("")},
+		"LEVEL=2":      {"LEVEL", defineValue("2")},
+		"NAME=\"a=b\"": {"NAME", defineValue("\"a=b\"")},
+	} {
+		name, v := project.ParseDefine(define)
+		if name != expected.name || !reflect.DeepEqual(v, expected.value) {
+			t.Errorf("ParseDefine(%q) = %q, %v", define, name, v)
+		}
+	}
+}
+
+func TestMergeCPPProfileDefines(t *testing.T) {
+	base := project.NewCPPProfile()
+	base.Defines = project.Defines{"NDEBUG": nil, "LEVEL": defineValue("1")}
+	base.IncludeDirectories = []string{"include"}
+	debug := project.NewCPPProfile()
+	debug.Defines = project.Defines{"DEBUG": nil, "LEVEL": defineValue("2")}
+	debug.Undefines = []string{"NDEBUG"}
+	debug.IncludeDirectories = []string{"include", "debug"}
+
+	merged := base.Merge(debug)
+	if !reflect.DeepEqual(merged.Defines, project.Defines{"DEBUG": nil, "LEVEL": defineValue("2")}) {
+		t.Errorf("unexpected merged defines %v", merged.Defines)
+	}
+	if !functional.ListEqual(merged.Defines.Names(), []string{"DEBUG", "LEVEL"}) {
+		t.Errorf("unexpected names %v", merged.Defines.Names())
+	}
+	if !functional.ListEqual(merged.IncludeDirectories, []string{"include", "debug"}) {
+		t.Errorf("unexpected include directories %v", merged.IncludeDirectories)
+	}
+	if !reflect.DeepEqual(base.Defines, project.Defines{"NDEBUG": nil, "LEVEL": defineValue("1")}) {
+		t.Errorf("merging must not change the base profile, got %v", base.Defines)
+	}
+}
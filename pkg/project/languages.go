@@ -4,6 +4,7 @@ type LanguageID int8
 
 const (
 	LangCPP LanguageID = iota
+	LangC
 	LangUnknown
 )
 
@@ -11,6 +12,8 @@ func LanguageIDFromString(IDStr string) LanguageID {
 	switch IDStr {
 	case "CPP":
 		return LangCPP
+	case "C":
+		return LangC
 	}
 	return LangUnknown
 }
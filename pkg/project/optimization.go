@@ -0,0 +1,75 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+type Optimization int8
+
+const (
+	OptimizeUnspecified Optimization = iota
+	OptimizeNone
+	OptimizeDebug
+	OptimizeSpeed
+	OptimizeSize
+)
+
+func OptimizationFromString(s string) (Optimization, error) {
+	switch s {
+	case "none":
+		return OptimizeNone, nil
+	case "debug":
+		return OptimizeDebug, nil
+	case "speed":
+		return OptimizeSpeed, nil
+	case "size":
+		return OptimizeSize, nil
+	}
+	return OptimizeUnspecified, fmt.Errorf("Unknown optimization '%s', expected one of none, debug, speed, size", s)
+}
+
+// Switch is a setting of a profile that is either on or off, unless it
+// is left to the profiles it is merged into.
+type Switch int8
+
+const (
+	SwitchUnspecified Switch = iota
+	SwitchOn
+	SwitchOff
+)
+
+func SwitchFromString(s string) (Switch, error) {
+	switch s {
+	case "on":
+		return SwitchOn, nil
+	case "off":
+		return SwitchOff, nil
+	}
+	return SwitchUnspecified, fmt.Errorf("Unknown setting '%s', expected on or off", s)
+}
+
+func SwitchFromBool(b bool) Switch {
+	if b {
+		return SwitchOn
+	}
+	return SwitchOff
+}
+
+// SanitizeNone disables the sanitizers of the profiles a profile is
+// merged into.
+const SanitizeNone = "none"
+
+var sanitizers = []string{"address", "undefined", "thread", "leak"}
+
+// CheckSanitizers tells if the sanitizers are known.
+func CheckSanitizers(names []string) error {
+	for _, name := range names {
+		if name != SanitizeNone && !functional.ListIn(sanitizers, name) {
+			return fmt.Errorf("Unknown sanitizer '%s', expected one of %s", name, strings.Join(sanitizers, ", "))
+		}
+	}
+	return nil
+}
@@ -1,19 +1,29 @@
 package project
 
+// RPathNone disables the run path of the targets when it is the RPath of
+// their profile.
+const RPathNone = "none"
+
 type Profile struct {
 	Name string
 
 	cppProfile *CPPProfile
+	cProfile   *CProfile
 
-	subProfiles   []*Profile
-	parentProfile *Profile
-	Sources       []FilesPattern
+	subProfiles     []*Profile
+	parentProfile   *Profile
+	Sources         []FilesPattern
+	Linkage         Linkage
+	Toolchain       string
+	ToolchainPrefix string
+	RPath           string
 }
 
 func NewProfile(name string) *Profile {
 	return &Profile{
 		Name:          name,
 		cppProfile:    nil,
+		cProfile:      nil,
 		subProfiles:   []*Profile{},
 		parentProfile: nil,
 	}
@@ -23,6 +33,7 @@ func DummyProfile(name string) *Profile {
 	return &Profile{
 		Name:          name,
 		cppProfile:    NewCPPProfile(),
+		cProfile:      NewCProfile(),
 		subProfiles:   []*Profile{},
 		parentProfile: nil,
 	}
@@ -37,11 +48,16 @@ func (p *Profile) NewSubProfile(name string) *Profile {
 
 func (p *Profile) Clone() *Profile {
 	np := &Profile{
-		Name:          p.Name,
-		cppProfile:    p.cppProfile.Clone(),
-		parentProfile: p.parentProfile,
-		subProfiles:   p.subProfiles,
-		Sources:       p.Sources,
+		Name:            p.Name,
+		cppProfile:      p.cppProfile.Clone(),
+		cProfile:        p.cProfile.Clone(),
+		parentProfile:   p.parentProfile,
+		subProfiles:     p.subProfiles,
+		Sources:         append([]FilesPattern{}, p.Sources...),
+		Linkage:         p.Linkage,
+		Toolchain:       p.Toolchain,
+		ToolchainPrefix: p.ToolchainPrefix,
+		RPath:           p.RPath,
 	}
 	return np
 }
@@ -50,7 +66,20 @@ func (p *Profile) Merge(op *Profile) *Profile {
 	np := p.Clone()
 	np.Name = op.Name
 	np.cppProfile = np.cppProfile.Merge(op.cppProfile)
+	np.cProfile = np.cProfile.Merge(op.cProfile)
 	np.Sources = append(np.Sources, op.Sources...)
+	if op.Linkage != LinkageUnspecified {
+		np.Linkage = op.Linkage
+	}
+	if op.Toolchain != "" {
+		np.Toolchain = op.Toolchain
+	}
+	if op.ToolchainPrefix != "" {
+		np.ToolchainPrefix = op.ToolchainPrefix
+	}
+	if op.RPath != "" {
+		np.RPath = op.RPath
+	}
 	return np
 }
 
@@ -68,6 +97,44 @@ func (p *Profile) GetCPPProfile() (cp *CPPProfile) {
 	return
 }
 
+func (p *Profile) SetCProfile(cp *CProfile) {
+	p.cProfile = cp
+}
+
+func (p *Profile) GetCProfile() (cp *CProfile) {
+	cp = p.cProfile
+	return
+}
+
 func (p *Profile) GetSubProfiles() []*Profile {
 	return p.subProfiles
 }
+
+// resolveIncludeDirectories makes the relative include directories of
+// the languages of the profile relative to dir.
+func (p *Profile) resolveIncludeDirectories(dir string) {
+	if p.cppProfile != nil {
+		p.cppProfile.resolveIncludeDirectories(dir)
+	}
+	if p.cProfile != nil {
+		p.cProfile.resolveIncludeDirectories(dir)
+	}
+}
+
+// ResolveIncludeDirectories makes the relative include directories of
+// all the profiles and platforms relative to dir.
+func ResolveIncludeDirectories(dir string, base *Profile, profiles ...map[string]*Profile) {
+	resolved := map[*Profile]bool{}
+	resolve := func(p *Profile) {
+		if p != nil && !resolved[p] {
+			p.resolveIncludeDirectories(dir)
+			resolved[p] = true
+		}
+	}
+	resolve(base)
+	for _, m := range profiles {
+		for _, p := range m {
+			resolve(p)
+		}
+	}
+}
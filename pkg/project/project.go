@@ -10,6 +10,7 @@ import (
 	"github.com/gueckmooh/bs/pkg/fsutil"
 	"github.com/gueckmooh/bs/pkg/functional"
 	"github.com/gueckmooh/bs/pkg/globbing"
+	log "github.com/gueckmooh/bs/pkg/logging"
 )
 
 const (
@@ -31,6 +32,7 @@ type Project struct {
 	DefaultProfile  string
 	Platforms       map[string]*Profile
 	DefaultPlatform string
+	PkgConfigPath   []string
 }
 
 type ComponentDependencyGraph struct {
@@ -39,7 +41,7 @@ type ComponentDependencyGraph struct {
 }
 
 func (p *Project) ComputeComponentDependencies() error {
-	fmt.Printf("%sCompute components dependencies...%s\n",
+	log.Info.Printf("%sCompute components dependencies...%s\n",
 		colors.ColorGray, colors.ColorReset)
 	g := alist.NewGraph[Component, alist.AttributeNone](alist.DirectedGraph)
 	visited := make(map[alist.VertexDescriptor]bool)
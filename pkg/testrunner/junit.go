@@ -0,0 +1,82 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/gueckmooh/bs/pkg/fsutil"
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// JUnitReport returns the results as a JUnit XML report with a single
+// test suite called name.
+func JUnitReport(name string, results []*Result) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:     name,
+		Tests:    len(results),
+		Failures: len(Failed(results)),
+	}
+	var total float64
+	for _, r := range results {
+		total += r.Duration.Seconds()
+		tc := junitTestCase{
+			Name:      r.Test.Name,
+			ClassName: name,
+			Time:      fmt.Sprintf("%.3f", r.Duration.Seconds()),
+			SystemOut: string(r.Output),
+		}
+		if r.Status != StatusPassed {
+			tc.Failure = &junitFailure{
+				Message: r.Message,
+				Type:    r.Status.String(),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Time = fmt.Sprintf("%.3f", total)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// WriteJUnitReport writes the JUnit XML report of the results in file.
+func WriteJUnitReport(file, name string, results []*Result) error {
+	data, err := JUnitReport(name, results)
+	if err != nil {
+		return err
+	}
+	err = fsutil.WriteFileAtomic(file, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("Could not write JUnit report '%s':\n\t%s", file, err.Error())
+	}
+	return nil
+}
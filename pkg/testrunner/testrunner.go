@@ -0,0 +1,214 @@
+package testrunner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/bucket"
+	"github.com/gueckmooh/bs/pkg/common/colors"
+)
+
+type Status int8
+
+const (
+	StatusPassed Status = iota
+	StatusFailed
+	StatusTimedOut
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPassed:
+		return "PASS"
+	case StatusFailed:
+		return "FAIL"
+	case StatusTimedOut:
+		return "TIMEOUT"
+	}
+	return "UNKNOWN"
+}
+
+// Test is an executable to run, it passes if it exits with status 0.
+type Test struct {
+	Name    string
+	Command string
+	Args    []string
+	Dir     string
+	Env     []string
+}
+
+type Result struct {
+	Test     *Test
+	Status   Status
+	Message  string
+	Output   []byte
+	Duration time.Duration
+}
+
+type runConfig struct {
+	jobs    int
+	timeout time.Duration
+	out     io.Writer
+}
+
+type RunOption func(c *runConfig)
+
+func WithJobs(j int) RunOption {
+	return func(c *runConfig) {
+		c.jobs = j
+	}
+}
+
+// WithTimeout kills the tests still running after d, no timeout is set
+// if d is 0.
+func WithTimeout(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.timeout = d
+	}
+}
+
+// WithOutput sets where the result of each test is printed as soon as it
+// is known, nothing is printed by default.
+func WithOutput(w io.Writer) RunOption {
+	return func(c *runConfig) {
+		c.out = w
+	}
+}
+
+func runTest(t *Test, timeout time.Duration) *Result {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(t.Command, t.Args...)
+	cmd.Dir = t.Dir
+	cmd.Env = t.Env
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// The test runs in its own process group, which is killed on timeout
+	// so that the processes the test started do not keep its output open
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	start := time.Now()
+	err := cmd.Start()
+	if err == nil {
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			case <-done:
+			}
+		}()
+		err = cmd.Wait()
+		close(done)
+	}
+	res := &Result{
+		Test:     t,
+		Status:   StatusPassed,
+		Duration: time.Since(start),
+		Output:   output.Bytes(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case ctx.Err() == context.DeadlineExceeded:
+		res.Status = StatusTimedOut
+		res.Message = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		res.Status = StatusFailed
+		res.Message = exitErr.Error()
+	default:
+		res.Status = StatusFailed
+		res.Message = fmt.Sprintf("could not run test: %s", err.Error())
+	}
+	return res
+}
+
+func (r *Result) print(w io.Writer) {
+	color := colors.ColorGreen
+	if r.Status != StatusPassed {
+		color = colors.ColorRed
+	}
+	fmt.Fprintf(w, "%s%-7s%s %s (%.2fs)\n", color, r.Status, colors.ColorReset,
+		r.Test.Name, r.Duration.Seconds())
+	if r.Status != StatusPassed {
+		fmt.Fprintf(w, "%s%s%s\n", colors.ColorGray, r.Message, colors.ColorReset)
+		w.Write(r.Output)
+		if len(r.Output) > 0 && r.Output[len(r.Output)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// Run runs the tests, at most jobs of them at once. The results are
+// returned in the order of the tests.
+func Run(tests []*Test, opts ...RunOption) []*Result {
+	config := runConfig{jobs: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.jobs < 1 {
+		config.jobs = 1
+	}
+
+	results := make([]*Result, len(tests))
+	var mutex sync.Mutex
+	b := bucket.NewBucket(int64(config.jobs))
+	for i, t := range tests {
+		i, t := i, t
+		b.Run(func() error {
+			res := runTest(t, config.timeout)
+			mutex.Lock()
+			defer mutex.Unlock()
+			results[i] = res
+			if config.out != nil {
+				res.print(config.out)
+			}
+			return nil
+		})
+	}
+	b.Wait()
+	return results
+}
+
+// Failed returns the results of the tests that did not pass.
+func Failed(results []*Result) []*Result {
+	var failed []*Result
+	for _, r := range results {
+		if r.Status != StatusPassed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// PrintSummary prints the number of tests that passed and the list of
+// the ones that failed.
+func PrintSummary(w io.Writer, results []*Result) {
+	failed := Failed(results)
+	var total time.Duration
+	for _, r := range results {
+		total += r.Duration
+	}
+	color := colors.ColorGreen
+	if len(failed) > 0 {
+		color = colors.ColorRed
+	}
+	fmt.Fprintf(w, "%s%d/%d tests passed%s (%.2fs)\n", color,
+		len(results)-len(failed), len(results), colors.ColorReset, total.Seconds())
+	for _, r := range failed {
+		fmt.Fprintf(w, "  %s%-7s%s %s\n", colors.ColorRed, r.Status, colors.ColorReset, r.Test.Name)
+	}
+}
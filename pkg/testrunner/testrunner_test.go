@@ -0,0 +1,95 @@
+package testrunner_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/testrunner"
+)
+
+func TestRun(t *testing.T) {
+	tests := []*testrunner.Test{
+		{Name: "pass", Command: "sh", Args: []string{"-c", "echo passing"}},
+		{Name: "fail", Command: "sh", Args: []string{"-c", "echo failing; exit 3"}},
+		{Name: "slow", Command: "sleep", Args: []string{"10"}},
+		{Name: "missing", Command: "./does-not-exist"},
+		{Name: "forking", Command: "sh", Args: []string{"-c", "sleep 10 & sleep 10"}},
+	}
+	results := testrunner.Run(tests, testrunner.WithJobs(4),
+		testrunner.WithTimeout(200*time.Millisecond))
+
+	expected := []testrunner.Status{
+		testrunner.StatusPassed,
+		testrunner.StatusFailed,
+		testrunner.StatusTimedOut,
+		testrunner.StatusFailed,
+		testrunner.StatusTimedOut,
+	}
+	for i, r := range results {
+		if r.Test != tests[i] {
+			t.Fatalf("result %d is the one of %s", i, r.Test.Name)
+		}
+		if r.Status != expected[i] {
+			t.Errorf("%s: expected status %s, got %s", r.Test.Name, expected[i], r.Status)
+		}
+	}
+	if string(results[0].Output) != "passing\n" || string(results[1].Output) != "failing\n" {
+		t.Errorf("output not captured: %q, %q", results[0].Output, results[1].Output)
+	}
+	for _, r := range []*testrunner.Result{results[2], results[4]} {
+		if r.Duration > 5*time.Second {
+			t.Errorf("timed out test %s ran for %s", r.Test.Name, r.Duration)
+		}
+	}
+	if n := len(testrunner.Failed(results)); n != 4 {
+		t.Errorf("expected 4 failed tests, got %d", n)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	results := testrunner.Run([]*testrunner.Test{
+		{Name: "pass", Command: "true"},
+		{Name: "fail", Command: "sh", Args: []string{"-c", "echo '<oops>'; exit 1"}},
+	})
+	data, err := testrunner.JUnitReport("project", results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Suites []struct {
+			Name      string `xml:"name,attr"`
+			Tests     int    `xml:"tests,attr"`
+			Failures  int    `xml:"failures,attr"`
+			TestCases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+				SystemOut string `xml:"system-out"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Suites) != 1 {
+		t.Fatalf("expected 1 test suite, got %d", len(report.Suites))
+	}
+	suite := report.Suites[0]
+	if suite.Name != "project" || suite.Tests != 2 || suite.Failures != 1 {
+		t.Errorf("unexpected test suite %s: %d tests, %d failures", suite.Name, suite.Tests, suite.Failures)
+	}
+	if suite.TestCases[0].Failure != nil {
+		t.Errorf("passing test reported as failed")
+	}
+	fail := suite.TestCases[1]
+	if fail.Failure == nil || !strings.Contains(fail.Failure.Message, "exit status 1") {
+		t.Errorf("failure of failing test not reported")
+	}
+	if fail.SystemOut != "<oops>\n" {
+		t.Errorf("unexpected output %q", fail.SystemOut)
+	}
+}
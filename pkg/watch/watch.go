@@ -0,0 +1,224 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+)
+
+const (
+	DefaultInterval = 500 * time.Millisecond
+	DefaultDebounce = 300 * time.Millisecond
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Poller watches files and the files of directories by polling their
+// modification time and size, so that it works on any filesystem.
+type Poller struct {
+	files    []string
+	dirs     []string
+	skip     []string
+	interval time.Duration
+	debounce time.Duration
+	base     *Poller
+	state    map[string]fileState
+}
+
+type PollerOption func(*Poller)
+
+func WithFiles(files ...string) PollerOption {
+	return func(p *Poller) {
+		p.files = append(p.files, files...)
+	}
+}
+
+// WithDirectories watches all the files in dirs and their subdirectories,
+// new files included.
+func WithDirectories(dirs ...string) PollerOption {
+	return func(p *Poller) {
+		p.dirs = append(p.dirs, dirs...)
+	}
+}
+
+// WithSkippedDirectories makes the directories in skip not be watched,
+// along with the .git directories.
+func WithSkippedDirectories(skip ...string) PollerOption {
+	return func(p *Poller) {
+		p.skip = append(p.skip, skip...)
+	}
+}
+
+func WithInterval(interval time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.interval = interval
+	}
+}
+
+// WithDebounce sets how long the files must be left unchanged before the
+// changes are reported, so that a burst of edits is reported at once.
+func WithDebounce(debounce time.Duration) PollerOption {
+	return func(p *Poller) {
+		p.debounce = debounce
+	}
+}
+
+// WithBaseline reports the changes made to the files watched by base
+// since it was created, instead of the ones made from now on. The files
+// of the skipped directories are not concerned, as they are written by
+// what happened in between.
+func WithBaseline(base *Poller) PollerOption {
+	return func(p *Poller) {
+		p.base = base
+	}
+}
+
+// NewPoller returns a poller reporting the changes made from now on.
+func NewPoller(opts ...PollerOption) *Poller {
+	p := &Poller{
+		interval: DefaultInterval,
+		debounce: DefaultDebounce,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.state = p.scan()
+	if p.base != nil {
+		for file := range p.state {
+			if p.base.watches(file) {
+				delete(p.state, file)
+			}
+		}
+		for file, s := range p.base.state {
+			if p.watches(file) {
+				p.state[file] = s
+			}
+		}
+		p.base = nil
+	}
+	return p
+}
+
+func isInDirectory(file, dir string) bool {
+	return strings.HasPrefix(file, dir+string(filepath.Separator))
+}
+
+// watches tells whether file is watched out of the skipped directories.
+func (p *Poller) watches(file string) bool {
+	for dir := filepath.Dir(file); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if p.isSkipped(dir) {
+			return false
+		}
+	}
+	return functional.ListIn(p.files, file) ||
+		functional.ListAnyOf(p.dirs, func(dir string) bool { return isInDirectory(file, dir) })
+}
+
+func (p *Poller) isSkipped(path string) bool {
+	if filepath.Base(path) == ".git" {
+		return true
+	}
+	for _, s := range p.skip {
+		if path == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Poller) scan() map[string]fileState {
+	state := make(map[string]fileState)
+	for _, file := range p.files {
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			state[file] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+	for _, dir := range p.dirs {
+		// The files may be removed while they are walked, the errors
+		// only mean that they are not there anymore
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if p.isSkipped(path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			state[path] = fileState{info.ModTime(), info.Size()}
+			return nil
+		})
+	}
+	return state
+}
+
+// getChanges returns the files that were modified, created or removed
+// between the states old and new.
+func getChanges(old, new map[string]fileState) []string {
+	var changes []string
+	for file, s := range new {
+		if o, ok := old[file]; !ok || o != s {
+			changes = append(changes, file)
+		}
+	}
+	for file := range old {
+		if _, ok := new[file]; !ok {
+			changes = append(changes, file)
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// Poll returns the files changed since the last changes were reported,
+// without waiting.
+func (p *Poller) Poll() []string {
+	state := p.scan()
+	changes := getChanges(p.state, state)
+	p.state = state
+	return changes
+}
+
+// sleep waits for d, and tells whether ctx is still not done then.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// Wait blocks until files change, then returns them once no other change
+// happened for the debounce duration. It returns nil if ctx is done
+// before.
+func (p *Poller) Wait(ctx context.Context) []string {
+	var changes []string
+	for len(changes) == 0 {
+		if !sleep(ctx, p.interval) {
+			return nil
+		}
+		changes = p.Poll()
+	}
+	for {
+		if !sleep(ctx, p.debounce) {
+			return nil
+		}
+		more := p.Poll()
+		if len(more) == 0 {
+			break
+		}
+		changes = append(changes, more...)
+	}
+	sort.Strings(changes)
+	return functional.ListUniq(changes)
+}
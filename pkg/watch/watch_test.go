@@ -0,0 +1,101 @@
+package watch_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gueckmooh/bs/pkg/functional"
+	"github.com/gueckmooh/bs/pkg/watch"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "src", "main.cpp")
+	header := filepath.Join(dir, "include", "main.hpp")
+	skipped := filepath.Join(dir, "src", ".git", "index")
+	writeFile(t, source, "int main() {}\n")
+	writeFile(t, header, "#pragma once\n")
+	writeFile(t, skipped, "")
+
+	p := watch.NewPoller(watch.WithDirectories(filepath.Join(dir, "src")),
+		watch.WithFiles(header))
+	if changes := p.Poll(); len(changes) != 0 {
+		t.Errorf("nothing changed, got %v", changes)
+	}
+
+	added := filepath.Join(dir, "src", "other.cpp")
+	writeFile(t, added, "")
+	writeFile(t, header, "#pragma once\n#define A\n")
+	writeFile(t, skipped, "changed")
+	os.Remove(source)
+	changes := p.Poll()
+	if !functional.ListEqual(changes, []string{header, source, added}) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+	if changes := p.Poll(); len(changes) != 0 {
+		t.Errorf("changes must be reported once, got %v", changes)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	build := filepath.Join(dir, "build")
+	source := filepath.Join(src, "main.cpp")
+	removed := filepath.Join(src, "old.cpp")
+	object := filepath.Join(build, "main.o")
+	writeFile(t, source, "int main() {}\n")
+	writeFile(t, removed, "")
+	writeFile(t, object, "")
+	opts := []watch.PollerOption{watch.WithDirectories(src), watch.WithFiles(object),
+		watch.WithSkippedDirectories(build)}
+
+	base := watch.NewPoller(opts...)
+	// Changed while building, the object being written by the build
+	writeFile(t, source, "int main() { return 0; }\n")
+	os.Remove(removed)
+	writeFile(t, object, "object")
+	p := watch.NewPoller(append(opts, watch.WithBaseline(base))...)
+	if changes := p.Poll(); !functional.ListEqual(changes, []string{source, removed}) {
+		t.Errorf("unexpected changes %v", changes)
+	}
+}
+
+func TestWaitDebounces(t *testing.T) {
+	dir := t.TempDir()
+	p := watch.NewPoller(watch.WithDirectories(dir),
+		watch.WithInterval(10*time.Millisecond), watch.WithDebounce(100*time.Millisecond))
+	go func() {
+		for _, name := range []string{"a", "b", "c"} {
+			ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0o644)
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+	changes := p.Wait(context.Background())
+	expected := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")}
+	if !functional.ListEqual(changes, expected) {
+		t.Errorf("the burst of edits must be reported at once, got %v", changes)
+	}
+}
+
+func TestWaitIsCancelled(t *testing.T) {
+	p := watch.NewPoller(watch.WithDirectories(t.TempDir()), watch.WithInterval(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if changes := p.Wait(ctx); changes != nil {
+		t.Errorf("no changes must be reported once cancelled, got %v", changes)
+	}
+}
@@ -0,0 +1,22 @@
+-- A project built with several profiles and platforms, each one having its
+-- own build directory.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
+
+project:Profile("Debug"):CPP():AddBuildOptions {"-g", "-O0"}
+project:Profile("Release"):CPP():AddBuildOptions {"-O2"}
+
+project:Platforms "Linux64"
+project:Platform("Linux64"):CPP():AddBuildOptions {"-m64"}
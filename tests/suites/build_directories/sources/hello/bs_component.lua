@@ -0,0 +1,7 @@
+components = require "components"
+
+component = components:NewComponent "hello_exe"
+
+component:Type       "executable"
+component:Languages  "CPP"
+component:AddSources "src/"
@@ -0,0 +1,133 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class BuildDirectoriesSuite(TestSuite):
+    def TestBaseBuildDirectory(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestProfileBuildDirectories(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Debug", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-o \.build/Debug/obj/hello_exe/src/main\.o"
+            )
+            self.runBS(["build", "-p", "Release"]).mustBeOk()
+            self.runCmd([".build/Debug/bin/hello_exe"]).mustBeOk()
+            self.runCmd([".build/Release/bin/hello_exe"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build/bin"]).mustBeNOk()
+
+    def TestPlatformBuildDirectories(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Debug", "-P", "Linux64"]).mustBeOk()
+            self.runBS(["build", "-P", "Linux64"]).mustBeOk()
+            self.runCmd([".build/Debug-Linux64/bin/hello_exe"]).mustBeOk()
+            self.runCmd([".build/Default-Linux64/bin/hello_exe"]).mustBeOk()
+
+    def TestDefaultProfileBuildDirectory(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build", "-p", "Default"]).mustBeOk().stdoutMustNotContain(
+                "Compiling", "Linking"
+            )
+            self.runCmd(["test", "-e", ".build/Default"]).mustBeNOk()
+
+    def TestSwitchingProfileDoesNotRebuild(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Debug"]).mustBeOk()
+            self.runBS(["build", "-p", "Release"]).mustBeOk().stdoutMustContain(
+                "Compiling"
+            )
+            self.runBS(["build", "-p", "Debug"]).mustBeOk().stdoutMustNotContain(
+                "Compiling", "Linking"
+            )
+
+    def TestBuildDirOption(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Debug", "--build-dir", "out"]).mustBeOk()
+            self.runCmd(["out/bin/hello_exe"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build/Debug"]).mustBeNOk()
+            self.runBS(
+                ["run", "-p", "Debug", "--build-dir", "out"]
+            ).mustBeOk().stdoutMustContain("Hello, World!").stdoutMustNotContain(
+                "Compiling"
+            )
+
+    def TestRunUsesConfiguration(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Release"]).mustBeOk()
+            self.runBS(["run", "-p", "Release"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            ).stdoutMustNotContain("Compiling")
+
+    def TestCleanConfiguration(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build", "-p", "Debug"]).mustBeOk()
+            self.runBS(["build", "-p", "Release"]).mustBeOk()
+            self.runBS(["clean", "-p", "Debug"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build/Debug"]).mustBeNOk()
+            self.runCmd([".build/Release/bin/hello_exe"]).mustBeOk()
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk()
+            self.runBS(["clean"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build"]).mustBeNOk()
+
+    def TestCleanBuildDir(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-dir", "out"]).mustBeOk()
+            self.runBS(["clean", "--build-dir", "out"]).mustBeOk()
+            self.runCmd(["test", "-e", "out"]).mustBeNOk()
+
+    def TestProjectOutputDirectories(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "bs_project.lua",
+                'project:BuildDirectory ".out"\nproject:BinDirectory "programs"\n',
+            )
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build", "-p", "Debug"]).mustBeOk()
+            self.runCmd([".out/programs/hello_exe"]).mustBeOk()
+            self.runCmd([".out/Debug/programs/hello_exe"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build"]).mustBeNOk()
+            self.runBS(["run", "-p", "Debug"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            ).stdoutMustNotContain("Compiling")
+            self.runBS(["clean", "-p", "Debug"]).mustBeOk()
+            self.runCmd(["test", "-e", ".out/Debug"]).mustBeNOk()
+            self.runBS(["clean"]).mustBeOk()
+            self.runCmd(["test", "-e", ".out"]).mustBeNOk()
+
+    def TestEnvironmentBuildDirectory(self):
+        with self.sandbox() as s:
+            self.appendFile("bs_project.lua", 'project:BuildDirectory ".out"\n')
+            env = {"BS_BUILD_DIRECTORY": "env_out"}
+            self.runBS(["build", "-p", "Release"], env=env).mustBeOk()
+            self.runCmd(["env_out/Release/bin/hello_exe"]).mustBeOk()
+            self.runCmd(["test", "-e", ".out"]).mustBeNOk()
+            self.runBS(["clean"], env=env).mustBeOk()
+            self.runCmd(["test", "-e", "env_out"]).mustBeNOk()
+
+    def TestBuildDirectoryContainingProject(self):
+        with self.sandbox() as s:
+            self.appendFile("bs_project.lua", 'project:BuildDirectory "."\n')
+            self.runBS(["build"]).mustBeNOk().stdoutMustContain(
+                "The build directory '.' contains the project"
+            )
+            self.runBS(["clean"]).mustBeNOk()
+            self.runCmd(["test", "-f", "bs_project.lua"]).mustBeOk()
+
+    def TestBuildDirOptionContainingProject(self):
+        with self.sandbox() as s:
+            self.runBS(["clean", "--build-dir", ".."]).mustBeNOk().stdoutMustContain(
+                "contains the project"
+            )
+            self.runCmd(["test", "-f", "bs_project.lua"]).mustBeOk()
+
+    def TestOutputDirectoryOutsideBuildDirectory(self):
+        with self.sandbox() as s:
+            self.appendFile("bs_project.lua", 'project:LibDirectory "../lib"\n')
+            self.runBS(["build"]).mustBeNOk().stdoutMustContain(
+                "The output directory '../lib' must be a subdirectory of the build directory"
+            )
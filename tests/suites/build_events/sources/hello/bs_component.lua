@@ -0,0 +1,16 @@
+components = require "components"
+fs = require "fs"
+
+component = components:NewComponent "hello_exe"
+
+component:Type       "executable"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:Requires "greet_lib"
+
+component:AddPostbuildAction(
+  function (targetPath)
+    fs.CopyFile(targetPath, targetPath .. ".copy")
+  end
+)
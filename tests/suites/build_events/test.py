@@ -0,0 +1,85 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class BuildEventsSuite(TestSuite):
+    def buildJSON(self):
+        return self.runBS(["build", "--build-upstream", "--output=json"])
+
+    def TestJSONEvents(self):
+        with self.sandbox() as s:
+            events = self.buildJSON().mustBeOk().events()
+            for c in ["base_lib", "greet_lib", "hello_exe"]:
+                self.findEvent(events, "component_started", component=c)
+                self.findEvent(
+                    events, "component_finished", component=c, status="built"
+                )
+            for f in ["name.cpp", "greet.cpp", "main.cpp"]:
+                e = [
+                    e
+                    for e in self.eventsOfKind(events, "file_compiled")
+                    if e["file"].endswith(f)
+                ]
+                self.AssertTrue(len(e) == 1)
+                self.AssertTrue(e[0]["exit_status"] == 0)
+                self.AssertTrue(e[0]["duration"] >= 0)
+            self.findEvent(
+                events, "target_linked", component="base_lib", archive=True
+            )
+            self.findEvent(
+                events,
+                "header_exported",
+                target=".build/include/greet_lib/greet/greet.hpp",
+            )
+            self.findEvent(
+                events,
+                "hook_run",
+                component="hello_exe",
+                stage="postbuild",
+                status="succeeded",
+            )
+            self.AssertTrue(all("time" in e for e in events))
+
+    def TestUpToDate(self):
+        with self.sandbox() as s:
+            self.buildJSON().mustBeOk()
+            events = self.buildJSON().mustBeOk().events()
+            self.AssertTrue(len(self.eventsOfKind(events, "file_compiled")) == 0)
+            for c in ["base_lib", "greet_lib", "hello_exe"]:
+                self.findEvent(
+                    events, "component_finished", component=c, status="up_to_date"
+                )
+
+    def TestCompileError(self):
+        with self.sandbox() as s:
+            self.writeFile(
+                "sources/hello/src/main.cpp", "int main(void) { return nope; }\n"
+            )
+            events = self.buildJSON().mustBeNOk().events()
+            compiled = self.findEvent(events, "file_compiled", component="hello_exe")
+            self.AssertTrue(compiled["exit_status"] not in [0, -1])
+            diagnostics = self.findEvent(events, "diagnostics", component="hello_exe")
+            self.AssertTrue("nope" in diagnostics["message"])
+            self.findEvent(
+                events, "component_finished", component="hello_exe", status="failed"
+            )
+
+    def TestJSONOutputWithLogs(self):
+        with self.sandbox() as s:
+            # The logs do not mix with the events
+            res = self.runBS(
+                ["build", "--build-upstream", "--output=json", "--verbose", "--debug"]
+            ).mustBeOk()
+            self.findEvent(
+                res.events(), "component_finished", component="hello_exe", status="built"
+            )
+            res.stderrMustContain("Info:", "Reading project...", "g++ ")
+
+    def TestTextOutput(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustMatch(
+                r"Compiling .*main\.cpp"
+            ).stdoutMustMatch(r"Archiving .*libbase_lib\.a").stdoutMustContain(
+                "--------------- Build successful for 'hello_exe'"
+            ).stdoutMustNotContain(
+                '"event"'
+            )
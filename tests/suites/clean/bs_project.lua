@@ -0,0 +1,18 @@
+-- A static library used by a shared library, itself used by an
+-- executable, and a headers component cloning its repository, cleaned
+-- with bs clean.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+staticProfile = project:Profile "Static"
+staticProfile:Linkage "static"
@@ -0,0 +1,91 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class CleanSuite(TestSuite):
+    def exists(self, path):
+        return self.runCmd(["test", "-e", path])
+
+    def TestCleanProject(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["clean"]).mustBeOk().stdoutMustMatch(r"Removing .*\.build")
+            self.exists(".build").mustBeNOk()
+
+    def TestCleanDefaultProfile(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["build", "--build-upstream", "-p", "Static"]).mustBeOk()
+            self.runBS(["clean", "-p", "Default"]).mustBeOk()
+            self.exists(".build/bin").mustBeNOk()
+            self.exists(".build/obj").mustBeNOk()
+            self.exists(".build/Static/bin/hello_exe").mustBeOk()
+
+    def TestCleanComponent(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["clean", "greet_lib"]).mustBeOk()
+            self.exists(".build/obj/greet_lib").mustBeNOk()
+            self.exists(".build/lib/libgreet_lib.so").mustBeNOk()
+            self.exists(".build/include/greet_lib").mustBeNOk()
+            self.exists(".build/lib/pkgconfig/greet_lib.pc").mustBeNOk()
+            self.exists(".build/lib/cmake/greet_lib").mustBeNOk()
+            self.exists(".build/obj/base_lib").mustBeOk()
+            self.exists(".build/lib/libbase_lib.a").mustBeOk()
+            self.exists(".build/bin/hello_exe").mustBeOk()
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustMatch(
+                r"Compiling .*greet\.cpp"
+            ).stdoutMustNotMatch(r"Compiling .*name\.cpp")
+
+    def TestCleanComponentUpstream(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["clean", "--upstream", "greet_lib"]).mustBeOk()
+            self.exists(".build/obj/greet_lib").mustBeNOk()
+            self.exists(".build/obj/base_lib").mustBeNOk()
+            self.exists(".build/lib/libbase_lib.a").mustBeNOk()
+            self.exists(".build/bin/hello_exe").mustBeOk()
+
+    def TestCleanComponentInAllConfigurations(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["build", "--build-upstream", "-p", "Static"]).mustBeOk()
+            self.runBS(["clean", "-p", "Static", "hello_exe"]).mustBeOk()
+            self.exists(".build/Static/bin/hello_exe").mustBeNOk()
+            self.exists(".build/bin/hello_exe").mustBeOk()
+            self.runBS(["clean", "greet_lib"]).mustBeOk()
+            self.exists(".build/Static/lib/libgreet_lib.a").mustBeNOk()
+            self.exists(".build/lib/libgreet_lib.so").mustBeNOk()
+
+    def TestUnknownComponent(self):
+        with self.sandbox() as s:
+            self.runBS(["clean", "unknown"]).mustBeNOk()
+
+    def TestDryRun(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["clean", "--dry-run", "hello_exe"]).mustBeOk().stdoutMustMatch(
+                r"Would remove .*\.build/obj/hello_exe"
+            ).stdoutMustMatch(
+                r"Would remove .*\.build/bin/hello_exe"
+            ).stdoutMustNotContain("Removing", "greet_lib")
+            self.exists(".build/bin/hello_exe").mustBeOk()
+            self.runBS(["clean", "-n"]).mustBeOk().stdoutMustMatch(
+                r"Would remove .*\.build"
+            )
+            self.exists(".build").mustBeOk()
+
+    def TestDistclean(self):
+        with self.sandbox() as s:
+            self.runCmd(["git", "init", "-q", "upstream"]).mustBeOk()
+            self.runCmd(["git", "init", "-q", "sources/base/mine"]).mustBeOk()
+            self.runBS(["build", "vendor"]).mustBeOk()
+            self.exists("sources/vendor/repository").mustBeOk()
+            self.runBS(["clean"]).mustBeOk()
+            self.exists("sources/vendor/repository").mustBeOk()
+            self.runBS(["clean", "--distclean", "--dry-run"]).mustBeOk().stdoutMustMatch(
+                r"Would remove .*sources/vendor/repository"
+            ).stdoutMustNotContain("mine")
+            self.runBS(["clean", "--distclean"]).mustBeOk()
+            self.exists("sources/vendor/repository").mustBeNOk()
+            self.exists("sources/base/mine").mustBeOk()
+            self.exists("upstream").mustBeOk()
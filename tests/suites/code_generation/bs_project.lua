@@ -0,0 +1,31 @@
+-- A project setting the optimization, debug symbols, sanitizers and LTO
+-- in its profiles.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+CPP = project:CPP()
+CPP:Dialect  "CPP17"
+CPP:Optimize "speed"
+CPP:Symbols  "off"
+
+debugProfile = project:Profile "Debug"
+debugProfile:CPP():Optimize "debug"
+debugProfile:CPP():Symbols  "on"
+debugProfile:CPP():Sanitize {"address", "undefined"}
+
+releaseProfile = project:Profile "Release"
+releaseProfile:CPP():LTO(true)
+
+project:Platforms {"NoSanitizer"}
+noSanitizer = project:Platform "NoSanitizer"
+noSanitizer:CPP():Sanitize "none"
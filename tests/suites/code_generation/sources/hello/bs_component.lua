@@ -0,0 +1,9 @@
+components = require "components"
+
+component = components:NewComponent "hello_exe"
+
+component:Type       "executable"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:Requires "base_lib"
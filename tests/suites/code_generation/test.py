@@ -0,0 +1,53 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class CodeGenerationSuite(TestSuite):
+    def TestBaseProfile(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"g\+\+ -std=c\+\+17 -O2 -g0 .*main\.cpp"
+            ).stdoutMustNotContain("-fsanitize", "-flto")
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestNamedProfileOverrides(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Debug", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-Og -g -fsanitize=address,undefined .*main\.cpp"
+            ).stdoutMustMatch(
+                r"g\+\+ -Og -g -fsanitize=address,undefined .*-o \S*hello_exe"
+            )
+            self.runCmd([".build/Debug/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestPlatformOverrides(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Debug", "-P", "NoSanitizer", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-Og -g .*main\.cpp"
+            ).stdoutMustNotContain("-fsanitize")
+
+    def TestLinkTimeOptimization(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Release", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-O2 -g0 -flto .*name\.cpp"
+            ).stdoutMustMatch(
+                r"g\+\+ -O2 -g0 -flto .*-o \S*hello_exe"
+            )
+            self.runCmd([".build/Release/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestSettingChangeRebuilds(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Release"]
+            ).mustBeOk().stdoutMustMatch(r"Compiling .*main\.cpp")
@@ -0,0 +1,18 @@
+-- A project whose compilation database is written.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
+
+releaseProfile = project:Profile "Release"
+releaseProfile:CPP():AddBuildOptions "-O2"
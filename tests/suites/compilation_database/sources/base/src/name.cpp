@@ -0,0 +1,5 @@
+#include <base/name.hpp>
+
+std::string name() {
+    return "World";
+}
@@ -0,0 +1,48 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class CompilationDatabaseSuite(TestSuite):
+    def listCommands(self):
+        script = (
+            "import json\n"
+            "for e in json.load(open('compile_commands.json')):\n"
+            "    print(e['file'] + ': ' + ' '.join(e['arguments']))\n"
+        )
+        return self.runCmd(["python3", "-c", script]).mustBeOk()
+
+    def TestCompdbWithoutBuilding(self):
+        with self.sandbox() as s:
+            self.runBS(["compdb"]).mustBeOk()
+            self.listCommands().stdoutMustContain(
+                "sources/base/src/name.cpp: g++ -std=c++17 ",
+                "sources/greet/src/greet.cpp: g++ -std=c++17 ",
+                "sources/hello/src/main.cpp: g++ -std=c++17 ",
+            )
+            self.runCmd(["test", "-e", ".build/bin/hello_exe"]).mustBeNOk()
+
+    def TestCompdbMatchesBuild(self):
+        with self.sandbox() as s:
+            self.runBS(["compdb", "-p", "Release"]).mustBeOk()
+            commands = [
+                line.split(": ", 1)[1]
+                for line in self.listCommands().stdout().splitlines()
+            ]
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Release", "--verbose"]
+            ).mustBeOk().stdoutMustContain(*commands)
+
+    def TestBuildWritesCompdb(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "greet_lib", "--build-upstream", "--compdb"]).mustBeOk()
+            self.listCommands().stdoutMustContain(
+                "sources/greet/src/greet.cpp"
+            ).stdoutMustNotContain("sources/hello/src/main.cpp")
+            self.runBS(["build", "hello_exe", "--build-upstream", "--compdb"]).mustBeOk()
+            self.listCommands().stdoutMustContain(
+                "sources/greet/src/greet.cpp", "sources/hello/src/main.cpp"
+            )
+
+    def TestBuildWithoutCompdb(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(["test", "-e", "compile_commands.json"]).mustBeNOk()
@@ -0,0 +1,24 @@
+-- A project configuring defines and include directories in profiles.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+CPP = project:CPP()
+CPP:Dialect "CPP17"
+CPP:AddDefines "NDEBUG"
+CPP:Defines { LEVEL = "1" }
+CPP:AddIncludeDirectories "common/"
+
+debug = project:Profile "Debug"
+debug:CPP():Dialect "CPP17"
+debug:CPP():UndefineDefine "NDEBUG"
+debug:CPP():AddDefines {"DEBUG", "LEVEL=2"}
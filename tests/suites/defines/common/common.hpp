@@ -0,0 +1,3 @@
+#pragma once
+
+#define COMMON_NAME "common"
@@ -0,0 +1,7 @@
+#pragma once
+
+#ifdef NDEBUG
+#define MODE "release"
+#else
+#define MODE "debug"
+#endif
@@ -0,0 +1,8 @@
+#include <iostream>
+#include <common.hpp>
+#include <config.hpp>
+
+int main(void) {
+    std::cout << COMMON_NAME << " " << MODE << " level " << LEVEL << std::endl;
+    return 0;
+}
@@ -0,0 +1,46 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class DefinesSuite(TestSuite):
+    def TestBaseProfile(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DLEVEL=1 -DNDEBUG .*main\.cpp"
+            ).stdoutMustMatch(
+                r"-Icommon -Isources/hello/include .*main\.cpp"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "common release level 1"
+            )
+
+    def TestNamedProfileOverrides(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "-p", "Debug", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DDEBUG -DLEVEL=2 .*main\.cpp"
+            ).stdoutMustNotContain("-DNDEBUG", "-DLEVEL=1")
+            self.runCmd([".build/Debug/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "common debug level 2"
+            )
+
+    def TestComponentOverrides(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/hello/bs_component.lua",
+                'component:CPP():AddDefines "LEVEL=3"\n',
+            )
+            self.runBS(["build", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DLEVEL=3 -DNDEBUG .*main\.cpp"
+            ).stdoutMustNotContain("-DLEVEL=1")
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "common release level 3"
+            )
+
+    def TestEmptyDefine(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/hello/bs_component.lua",
+                'component:CPP():AddDefines {"EMPTY=", "UNSET"}\n',
+            )
+            self.runBS(["build", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DEMPTY= -DLEVEL=1 -DNDEBUG -DUNSET .*main\.cpp"
+            )
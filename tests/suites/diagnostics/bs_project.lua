@@ -0,0 +1,16 @@
+-- A library whose sources trigger warnings, used by an executable, to
+-- check the diagnostics reported by bs build.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:CPP():AddBuildOptions {"-Wall"}
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
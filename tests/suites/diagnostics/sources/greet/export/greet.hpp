@@ -0,0 +1,6 @@
+#pragma once
+
+#include <string>
+
+std::string greet();
+std::string name();
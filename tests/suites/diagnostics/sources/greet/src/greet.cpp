@@ -0,0 +1,6 @@
+#include <greet/greet.hpp>
+
+std::string greet() {
+    int unused;
+    return "Hello";
+}
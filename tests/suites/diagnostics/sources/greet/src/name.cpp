@@ -0,0 +1,6 @@
+#include <greet/greet.hpp>
+
+std::string name() {
+    int unused;
+    return "World";
+}
@@ -0,0 +1,7 @@
+#include <iostream>
+#include <greet/greet.hpp>
+
+int main(void) {
+    std::cout << greet() << ", " << name() << "!" << std::endl;
+    return 0;
+}
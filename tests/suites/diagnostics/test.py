@@ -0,0 +1,56 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class DiagnosticsSuite(TestSuite):
+    def build(self, *options):
+        return self.runBS(["build", "--build-upstream", "-j", "2"] + list(options))
+
+    def TestWarningsSummary(self):
+        with self.sandbox() as s:
+            self.build().mustBeOk().stdoutMustContain(
+                "0 errors, 2 warnings in 2 files"
+            ).stderrMustContain("[-Wunused-variable]")
+            self.build().mustBeOk().stdoutMustNotContain("warnings in")
+
+    def TestErrorSummary(self):
+        with self.sandbox() as s:
+            self.build().mustBeOk()
+            self.writeFile(
+                "sources/hello/src/main.cpp", "int main(void) { return nope; }\n"
+            )
+            self.build().mustBeNOk().stdoutMustContain(
+                "1 error, 0 warnings in 1 file"
+            ).stderrMustContain("nope")
+
+    def TestJSONDiagnostics(self):
+        with self.sandbox() as s:
+            events = self.build("--output=json").mustBeOk().events()
+            e = self.findEvent(events, "diagnostics", file="sources/greet/src/name.cpp")
+            self.AssertTrue(len(e["diagnostics"]) == 1)
+            d = e["diagnostics"][0]
+            self.AssertTrue(d["file"] == "sources/greet/src/name.cpp")
+            self.AssertTrue(d["line"] == 4)
+            self.AssertTrue(d["severity"] == "warning")
+            self.AssertTrue(d["option"] == "-Wunused-variable")
+            summary = self.findEvent(events, "build_summary")["summary"]
+            self.AssertTrue(summary == {"errors": 0, "warnings": 2, "files": 2})
+
+    def TestSARIFReport(self):
+        with self.sandbox() as s:
+            self.build("--sarif", "report.sarif").mustBeOk().stdoutMustMatch(
+                r"Writing .*report\.sarif"
+            )
+            report = self.readJSON("report.sarif")
+            self.AssertTrue(report["version"] == "2.1.0")
+            run = report["runs"][0]
+            self.AssertTrue(run["tool"]["driver"]["rules"] == [{"id": "-Wunused-variable"}])
+            results = run["results"]
+            self.AssertTrue(len(results) == 2)
+            self.AssertTrue(all(r["level"] == "warning" for r in results))
+            uris = sorted(
+                r["locations"][0]["physicalLocation"]["artifactLocation"]["uri"]
+                for r in results
+            )
+            self.AssertTrue(
+                uris == ["sources/greet/src/greet.cpp", "sources/greet/src/name.cpp"]
+            )
@@ -0,0 +1,11 @@
+components = require "components"
+
+component = components:NewComponent "base_lib"
+
+component:Type       "library"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "base/[DIRS]/*.hpp"
+}
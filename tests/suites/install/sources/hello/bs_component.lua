@@ -0,0 +1,13 @@
+components = require "components"
+
+component = components:NewComponent "hello_exe"
+
+component:Type       "executable"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:Requires "greet_lib"
+
+component:Install {
+  ["data/*.conf"] = "share/hello/*.conf"
+}
@@ -0,0 +1,11 @@
+#include <iostream>
+#include <greet/greet.hpp>
+
+int main(void) {
+    if (greet() != "Hello, World!") {
+        std::cerr << "unexpected greeting: " << greet() << std::endl;
+        return 1;
+    }
+    std::cout << "greeting checked" << std::endl;
+    return 0;
+}
@@ -0,0 +1,79 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class InstallSuite(TestSuite):
+    def TestInstall(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "--prefix=/usr", "--destdir=stage"]
+            ).mustBeOk().stdoutMustMatch(r"Installing .*/stage/usr/bin/hello_exe")
+            for f in [
+                "bin/hello_exe",
+                "lib/libgreet_lib.so",
+                "lib/libbase_lib.so",
+                "include/greet/greet.hpp",
+                "include/base/name.hpp",
+                "share/hello/hello.conf",
+            ]:
+                self.runCmd(["test", "-f", "stage/usr/" + f]).mustBeOk()
+            self.runCmd(["test", "-e", "stage/usr/bin/test_greet"]).mustBeNOk()
+            self.runCmd(
+                ["cat", "stage/usr/include/greet/greet.hpp"]
+            ).mustBeOk().stdoutMustContain("std::string greet();")
+            self.runCmd(["stage/usr/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+            self.writeFile(
+                "client.cpp",
+                "#include <greet/greet.hpp>\nint main() { return greet().empty(); }\n",
+            )
+            self.runCmd(
+                [
+                    "g++",
+                    "client.cpp",
+                    "-Istage/usr/include",
+                    "-Lstage/usr/lib",
+                    "-lgreet_lib",
+                    "-o",
+                    "client",
+                ]
+            ).mustBeOk()
+
+    def TestDestDirFromEnvironment(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "--prefix", "/opt/hello"], env={"DESTDIR": "stage"}
+            ).mustBeOk()
+            self.runCmd(["test", "-f", "stage/opt/hello/bin/hello_exe"]).mustBeOk()
+
+    def TestLibDirRewritesRPath(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "--prefix=/usr", "--destdir=stage", "--libdir=lib64"]
+            ).mustBeOk().stdoutMustMatch(r"Linking .*/stage/usr/bin/hello_exe")
+            self.runCmd(
+                ["readelf", "-d", "stage/usr/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain("[$ORIGIN/../lib64]")
+            self.runCmd(
+                ["readelf", "-d", ".build/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain("[$ORIGIN/../lib]")
+            self.runCmd(["stage/usr/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestInstallComponent(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "greet_lib", "--prefix=/usr", "--destdir=stage"]
+            ).mustBeOk()
+            self.runCmd(["test", "-f", "stage/usr/lib/libgreet_lib.so"]).mustBeOk()
+            self.runCmd(["test", "-f", "stage/usr/lib/libbase_lib.so"]).mustBeOk()
+            self.runCmd(["test", "-e", "stage/usr/bin/hello_exe"]).mustBeNOk()
+
+    def TestRelativePrefix(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "--prefix=usr", "--destdir=stage"]
+            ).mustBeNOk().stdoutMustContain(
+                "The install prefix must be an absolute path"
+            )
@@ -0,0 +1,39 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class InterruptSuite(TestSuite):
+    COMPILER = {"CXX": "./slow-g++"}
+    OBJECT = ".build/obj/hello_exe/src/main.o"
+
+    def changeMain(self):
+        self.sleep(1)
+        self.writeFile(
+            "sources/hello/src/main.cpp",
+            "#include <iostream>\n\nint main(void) {\n"
+            '    std::cout << "Hello, Moon!" << std::endl;\n    return 0;\n}\n',
+        )
+
+    def TestInterruptCompilation(self):
+        with self.sandbox() as s:
+            self.runBS(["build"], env=self.COMPILER).mustBeOk()
+            self.changeMain()
+            with self.runBSInBackground(
+                ["build"], env=dict(self.COMPILER, SLOW="30")
+            ) as b:
+                b.waitForOutput("Compiling")
+                self.sleep(1)
+                b.interrupt().mustBeNOk().stdoutMustContain(
+                    "--------------- Build interrupted"
+                )
+            # No partial or stale output is left behind
+            self.runCmd(["find", ".build", "-name", ".bs-tmp-*"]).mustBeOk().stdoutMustNotContain(
+                ".bs-tmp-"
+            )
+            self.runCmd(["test", "!", "-e", self.OBJECT]).mustBeOk()
+
+            self.runBS(["build"], env=self.COMPILER).mustBeOk().stdoutMustMatch(
+                r"Compiling .*main\.cpp"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, Moon!"
+            )
@@ -0,0 +1,14 @@
+-- Independent components, some of them depending on others, built with
+-- bs build --keep-going after failures.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
@@ -0,0 +1,13 @@
+components = require "components"
+
+component = components:NewComponent "broken_exe"
+
+component:Type       "executable"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:AddPrebuildAction(
+  function ()
+    error("Prebuild failed")
+  end
+)
@@ -0,0 +1,6 @@
+#include <iostream>
+
+int main(void) {
+    std::cout << "Other" << std::endl;
+    return 0;
+}
@@ -0,0 +1,7 @@
+#include <iostream>
+#include <base/name.hpp>
+
+int main(void) {
+    std::cout << "Bye, " << name() << "!" << std::endl;
+    return 0;
+}
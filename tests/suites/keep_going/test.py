@@ -0,0 +1,79 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class KeepGoingSuite(TestSuite):
+    BROKEN = "int broken(void) { return nope; }\n"
+
+    def build(self, *options):
+        return self.runBS(
+            ["build", "--build-upstream", "hello_exe", "other_exe", "bye_exe"]
+            + list(options)
+        )
+
+    def TestKeepGoing(self):
+        with self.sandbox() as s:
+            self.writeFile("sources/greet/src/greet.cpp", self.BROKEN)
+            self.build("-k").mustBeNOk().stdoutMustContain(
+                "--------------- Failed to build component 'greet_lib'",
+                "--------------- Skipped component 'hello_exe': Dependency 'greet_lib' was not built",
+                "--------------- Build successful for 'other_exe'",
+                "--------------- Build successful for 'bye_exe'",
+                "Build of component 'greet_lib' failed",
+            ).stdoutMustNotMatch(r"Linking .*hello_exe").stderrMustContain("nope")
+            self.runCmd([".build/bin/other_exe"]).mustBeOk().stdoutMustContain("Other")
+            self.runCmd([".build/bin/bye_exe"]).mustBeOk().stdoutMustContain(
+                "Bye, World!"
+            )
+
+            self.writeFile(
+                "sources/greet/src/greet.cpp",
+                '#include <base/name.hpp>\n#include <greet/greet.hpp>\n\n'
+                'std::string greet() {\n    return "Hello, " + name() + "!";\n}\n',
+            )
+            self.build("-k").mustBeOk().stdoutMustMatch(
+                r"Linking .*hello_exe"
+            ).stdoutMustContain("--------------- Nothing to be done for 'other_exe'")
+
+    def TestSeveralFailures(self):
+        with self.sandbox() as s:
+            self.writeFile("sources/greet/src/greet.cpp", self.BROKEN)
+            self.writeFile("sources/other/src/main.cpp", self.BROKEN)
+            self.build("--keep-going", "-j", "4").mustBeNOk().stdoutMustContain(
+                "--------------- Failed to build component 'greet_lib'",
+                "--------------- Failed to build component 'other_exe'",
+                "--------------- Skipped component 'hello_exe'",
+                "--------------- Build successful for 'bye_exe'",
+                "Build of components",
+            )
+
+    def TestStopsWithoutKeepGoing(self):
+        with self.sandbox() as s:
+            self.writeFile("sources/base/src/name.cpp", self.BROKEN)
+            self.build().mustBeNOk().stdoutMustContain(
+                "--------------- Failed to build component 'base_lib'"
+            ).stdoutMustNotContain("Skipped component", "Build successful")
+
+    def TestPrebuildFailure(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "-k", "broken_exe", "other_exe"]
+            ).mustBeNOk().stdoutMustContain(
+                "--------------- Failed to build component 'broken_exe'",
+                "--------------- Build successful for 'other_exe'",
+            ).stderrMustContain(
+                "Prebuild failed"
+            )
+
+    def TestJSONEvents(self):
+        with self.sandbox() as s:
+            self.writeFile("sources/greet/src/greet.cpp", self.BROKEN)
+            events = self.build("-k", "--output=json").mustBeNOk().events()
+            self.findEvent(
+                events, "component_finished", component="greet_lib", status="failed"
+            )
+            self.findEvent(
+                events, "component_finished", component="hello_exe", status="skipped"
+            )
+            self.findEvent(
+                events, "component_finished", component="other_exe", status="built"
+            )
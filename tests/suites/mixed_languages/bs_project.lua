@@ -0,0 +1,18 @@
+-- A project mixing C and C++ sources, inside a component and between
+-- components.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     {"CPP", "C"}  -- Enables C++ and C compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
+project:C():Dialect "C11"
+project:C():AddBuildOptions "-Wall"
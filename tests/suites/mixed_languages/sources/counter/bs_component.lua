@@ -0,0 +1,9 @@
+components = require "components"
+
+component = components:NewComponent "counter_exe"
+
+component:Type       "executable"
+component:Languages  "C"
+component:AddSources "src/"
+
+component:Requires "message_lib"
@@ -0,0 +1,8 @@
+#include <stdio.h>
+#include <string.h>
+#include <message/message.h>
+
+int main(void) {
+    printf("%zu\n", strlen(message()));
+    return 0;
+}
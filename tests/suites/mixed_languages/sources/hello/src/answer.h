@@ -0,0 +1,11 @@
+#pragma once
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+int answer(void);
+
+#ifdef __cplusplus
+}
+#endif
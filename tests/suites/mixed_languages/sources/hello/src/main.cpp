@@ -0,0 +1,9 @@
+#include <iostream>
+#include <message/message.h>
+#include "answer.h"
+
+int main(void) {
+    std::cout << message() << std::endl;
+    std::cout << "The answer is " << answer() << std::endl;
+    return 0;
+}
@@ -0,0 +1,11 @@
+components = require "components"
+
+component = components:NewComponent "message_lib"
+
+component:Type       "library"
+component:Languages  "C"
+component:AddSources "src/"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.h"] = "message/[DIRS]/*.h"
+}
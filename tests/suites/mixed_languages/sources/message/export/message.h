@@ -0,0 +1,11 @@
+#pragma once
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+const char *message(void);
+
+#ifdef __cplusplus
+}
+#endif
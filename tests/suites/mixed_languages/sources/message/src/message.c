@@ -0,0 +1,5 @@
+#include <message/message.h>
+
+const char *message(void) {
+    return "Hello from C!";
+}
@@ -0,0 +1,40 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class MixedLanguagesSuite(TestSuite):
+    def TestBuildMixedComponent(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain("Hello from C!", "The answer is 42")
+
+    def TestDriversAndDialects(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"gcc -std=c11 -Wall .*sources/hello/src/answer.c"
+            ).stdoutMustMatch(
+                r"g\+\+ -std=c\+\+17 .*sources/hello/src/main.cpp"
+            ).stdoutMustMatch(
+                r"gcc -shared .*-o \.build/lib/"
+            ).stdoutMustMatch(
+                r"g\+\+ .*-o \.build/bin/hello_exe"
+            )
+
+    def TestBuildCOnlyExecutable(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "counter_exe", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(r"gcc .*-o \.build/bin/counter_exe")
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/counter_exe"]
+            ).mustBeOk().stdoutMustContain("13")
+
+    def TestNothingToBeDone(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustNotContain(
+                "Compiling", "Linking"
+            )
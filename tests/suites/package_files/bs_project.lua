@@ -0,0 +1,15 @@
+-- A project whose libraries can be used with pkg-config and CMake.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
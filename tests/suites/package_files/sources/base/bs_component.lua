@@ -0,0 +1,13 @@
+components = require "components"
+
+component = components:NewComponent "base_lib"
+
+component:Type       "static"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:CPP():AddLinkOptions "-lm"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "base/[DIRS]/*.hpp"
+}
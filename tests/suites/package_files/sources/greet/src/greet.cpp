@@ -0,0 +1,7 @@
+#include <base/name.hpp>
+#include <greet/greet.hpp>
+#include <version/version.hpp>
+
+std::string greet() {
+    return "Hello, " + name() + "!";
+}
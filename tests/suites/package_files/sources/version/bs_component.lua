@@ -0,0 +1,9 @@
+components = require "components"
+
+component = components:NewComponent "version_headers"
+
+component:Type       "headers"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "version/[DIRS]/*.hpp"
+}
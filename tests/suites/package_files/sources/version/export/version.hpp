@@ -0,0 +1,3 @@
+#pragma once
+
+#define GREET_VERSION "0.0.1"
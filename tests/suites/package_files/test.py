@@ -0,0 +1,107 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class PackageFilesSuite(TestSuite):
+    def TestBuildTreePkgConfig(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustMatch(
+                r"Writing .*\.build/lib/pkgconfig/greet_lib\.pc"
+            ).stdoutMustMatch(
+                r"Writing .*\.build/lib/cmake/greet_lib/greet_libConfig\.cmake"
+            ).stdoutMustMatch(
+                r"Writing .*\.build/lib/pkgconfig/version_headers\.pc"
+            ).stdoutMustNotContain("hello_exe.pc")
+            self.runCmd(
+                [
+                    "env",
+                    "PKG_CONFIG_PATH=.build/lib/pkgconfig",
+                    "pkg-config",
+                    "--cflags",
+                    "--libs",
+                    "greet_lib",
+                ]
+            ).mustBeOk().stdoutMustMatch(
+                r"-I/.*/\.build/include/greet_lib"
+            ).stdoutMustMatch(
+                r"-I/.*/\.build/include/version_headers"
+            ).stdoutMustContain(
+                "-lgreet_lib", "-lbase_lib", "-lm"
+            )
+            self.writeFile(
+                "client.cpp",
+                "#include <greet/greet.hpp>\nint main() { return greet().empty(); }\n",
+            )
+            self.runCmd(
+                [
+                    "sh",
+                    "-c",
+                    "g++ client.cpp $(PKG_CONFIG_PATH=.build/lib/pkgconfig pkg-config --cflags --libs greet_lib) -o client",
+                ]
+            ).mustBeOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", "./client"]
+            ).mustBeOk()
+
+    def TestBuildTreeCMake(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(
+                ["cat", ".build/lib/cmake/greet_lib/greet_libConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                'include("${CMAKE_CURRENT_LIST_DIR}/../base_lib/base_libConfig.cmake")',
+                'get_filename_component(_bs_prefix "${CMAKE_CURRENT_LIST_DIR}/../../.." ABSOLUTE)',
+                "add_library(greet_lib::greet_lib SHARED IMPORTED)",
+                'IMPORTED_LOCATION "${_bs_prefix}/lib/libgreet_lib.so"',
+                'INTERFACE_INCLUDE_DIRECTORIES "${_bs_prefix}/include/greet_lib"',
+                'INTERFACE_LINK_LIBRARIES "base_lib::base_lib;version_headers::version_headers"',
+            )
+            self.runCmd(
+                ["cat", ".build/lib/cmake/base_lib/base_libConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                "add_library(base_lib::base_lib STATIC IMPORTED)",
+                'INTERFACE_LINK_LIBRARIES "-lm"',
+            )
+            self.runCmd(
+                ["cat", ".build/lib/cmake/version_headers/version_headersConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                "add_library(version_headers::version_headers INTERFACE IMPORTED)"
+            ).stdoutMustNotContain("IMPORTED_LOCATION")
+
+    def TestNotRewritten(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustNotContain(
+                "Writing"
+            )
+
+    def TestInstalled(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["install", "--prefix=/usr", "--destdir=stage", "--libdir=lib64"]
+            ).mustBeOk()
+            self.runCmd(
+                ["cat", "stage/usr/lib64/pkgconfig/greet_lib.pc"]
+            ).mustBeOk().stdoutMustContain(
+                "prefix=/usr\n",
+                "libdir=${prefix}/lib64\n",
+                "includedir=${prefix}/include\n",
+                "Requires: base_lib, version_headers\n",
+                "Libs: -L${libdir} -lgreet_lib\n",
+                "Version: 0.0.1\n",
+            )
+            self.runCmd(
+                ["cat", "stage/usr/lib64/cmake/greet_lib/greet_libConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                'IMPORTED_LOCATION "${_bs_prefix}/lib64/libgreet_lib.so"',
+                'INTERFACE_INCLUDE_DIRECTORIES "${_bs_prefix}/include"',
+            )
+            self.runCmd(
+                [
+                    "env",
+                    "PKG_CONFIG_PATH=stage/usr/lib64/pkgconfig",
+                    "PKG_CONFIG_SYSROOT_DIR=stage",
+                    "pkg-config",
+                    "--cflags",
+                    "greet_lib",
+                ]
+            ).mustBeOk().stdoutMustContain("-Istage/usr/include")
@@ -0,0 +1,17 @@
+-- A project requiring system packages found with pkg-config.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:PkgConfigPath "pkgconfig/"
+
+project:CPP():Dialect "CPP17"
@@ -0,0 +1,7 @@
+#include <iostream>
+#include <image/image.hpp>
+
+int main(void) {
+    std::cout << "fakepng " << HAVE_FAKEPNG << ": " << diagonal(3, 4) << std::endl;
+    return 0;
+}
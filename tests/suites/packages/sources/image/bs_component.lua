@@ -0,0 +1,13 @@
+components = require "components"
+
+component = components:NewComponent "image_lib"
+
+component:Type       "static"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:RequiresPackage "fakepng >= 1.6"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "image/[DIRS]/*.hpp"
+}
@@ -0,0 +1,7 @@
+#pragma once
+
+#ifndef HAVE_FAKEPNG
+#error "fakepng is required"
+#endif
+
+double diagonal(double width, double height);
@@ -0,0 +1,6 @@
+#include <cmath>
+#include <image/image.hpp>
+
+double diagonal(double width, double height) {
+    return std::sqrt(width * width + height * height);
+}
@@ -0,0 +1,62 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class PackagesSuite(TestSuite):
+    def TestFlagsPropagated(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DHAVE_FAKEPNG=1637 .*image\.cpp"
+            ).stdoutMustMatch(
+                r"-DHAVE_FAKEPNG=1637 .*main\.cpp"
+            ).stdoutMustMatch(
+                r"-o \S*hello_exe .*-lm"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "fakepng 1637: 5"
+            )
+
+    def TestDependencyLinkOptions(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/image/bs_component.lua",
+                'component:CPP():AddPublicLinkOptions {"-pthread", "-Wl,-z,now"}\n',
+            )
+            self.runBS(["build", "--build-upstream", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-o \S*hello_exe .*-pthread -Wl,-z,now"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk()
+
+    def TestPackageFiles(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(
+                ["cat", ".build/lib/pkgconfig/image_lib.pc"]
+            ).mustBeOk().stdoutMustContain("Requires: fakepng >= 1.6\n")
+            self.runCmd(
+                ["cat", ".build/lib/cmake/image_lib/image_libConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                'INTERFACE_COMPILE_OPTIONS "-I/opt/fakepng/include;-DHAVE_FAKEPNG=1637"',
+                'INTERFACE_LINK_LIBRARIES "-lm"',
+            )
+
+    def TestMissingPackage(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/image/bs_component.lua",
+                'component:RequiresPackage "libdoesnotexist"\n',
+            )
+            self.runBS(["build", "--build-upstream"]).mustBeNOk().stderrMustContain(
+                "Component 'image_lib' requires a missing package",
+                "Package 'libdoesnotexist' not found",
+            )
+
+    def TestTooOldPackage(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/image/bs_component.lua",
+                'component:RequiresPackage "fakepng >= 2.0"\n',
+            )
+            self.runBS(["build", "--build-upstream"]).mustBeNOk().stderrMustContain(
+                "Package 'fakepng >= 2.0' not found",
+                "1.6.37",
+            )
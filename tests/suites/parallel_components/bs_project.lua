@@ -0,0 +1,14 @@
+-- Two independent libraries used by the same executable, so that they
+-- can be compiled concurrently.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
@@ -0,0 +1,5 @@
+#include <farewell/goodbye.hpp>
+
+std::string goodbye() {
+    return "Goodbye, World!";
+}
@@ -0,0 +1,5 @@
+#include <greetings/hello.hpp>
+
+std::string hello() {
+    return "Hello, World!";
+}
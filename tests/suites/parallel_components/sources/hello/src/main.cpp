@@ -0,0 +1,9 @@
+#include <iostream>
+#include <farewell/goodbye.hpp>
+#include <greetings/hello.hpp>
+
+int main(void) {
+    std::cout << hello() << std::endl;
+    std::cout << goodbye() << std::endl;
+    return 0;
+}
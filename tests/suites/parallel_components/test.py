@@ -0,0 +1,53 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class ParallelComponentsSuite(TestSuite):
+    def TestBuildUpstream(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain(
+                "Hello, World!", "Goodbye, World!"
+            )
+
+    def TestBuildUpstreamParallel(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "-j", "4"]).mustBeOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain(
+                "Hello, World!", "Goodbye, World!"
+            )
+
+    def TestBuildSeveralComponents(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "greetings_lib", "farewell_lib", "hello_exe", "-j", "4"]
+            ).mustBeOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/hello_exe"]
+            ).mustBeOk()
+
+    def TestNothingToBeDone(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "-j", "4"]).mustBeOk()
+            self.runBS(
+                ["build", "--build-upstream", "-j", "4"]
+            ).mustBeOk().stdoutMustNotContain("Compiling", "Linking")
+
+    def TestRelinkOnLibraryChange(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "-j", "4"]).mustBeOk()
+            self.appendFile(
+                "sources/greetings/src/hello.cpp", "\nint unused = 0;\n"
+            )
+            self.runBS(
+                ["build", "--build-upstream", "-j", "4"]
+            ).mustBeOk().stdoutMustMatch(
+                r"Compiling .*sources/greetings/src/hello.cpp"
+            ).stdoutMustMatch(
+                r"Linking .*\.build/bin/hello_exe"
+            ).stdoutMustNotMatch(
+                r"Compiling .*sources/hello/src/main.cpp"
+            )
@@ -0,0 +1,15 @@
+components = require "components"
+
+component = components:NewComponent "base_lib"
+
+component:Type       "static"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:CPP():AddDefines "BASE_INTERNAL"
+component:CPP():AddPublicDefines "USE_BASE=1"
+component:CPP():AddPublicLinkOptions "-pthread"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "base/[DIRS]/*.hpp"
+}
@@ -0,0 +1,9 @@
+#pragma once
+
+#include <string>
+
+#ifndef USE_BASE
+#error "USE_BASE must be defined by base_lib"
+#endif
+
+std::string name();
@@ -0,0 +1,9 @@
+#include <base/name.hpp>
+
+#ifndef BASE_INTERNAL
+#error "BASE_INTERNAL must be defined"
+#endif
+
+std::string name() {
+    return "World";
+}
@@ -0,0 +1,15 @@
+components = require "components"
+
+component = components:NewComponent "greet_lib"
+
+component:Type       "library"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:Requires "base_lib"
+
+component:CPP():AddPublicBuildOptions "-DGREET_API=1"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "greet/[DIRS]/*.hpp"
+}
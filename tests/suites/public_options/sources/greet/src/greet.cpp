@@ -0,0 +1,10 @@
+#include <base/name.hpp>
+#include <greet/greet.hpp>
+
+#ifdef BASE_INTERNAL
+#error "BASE_INTERNAL must be private to base_lib"
+#endif
+
+std::string greet() {
+    return "Hello, " + name() + "!";
+}
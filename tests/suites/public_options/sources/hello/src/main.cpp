@@ -0,0 +1,11 @@
+#include <iostream>
+#include <greet/greet.hpp>
+
+#if !defined(USE_BASE) || !defined(GREET_API)
+#error "The public defines of the dependencies must be defined"
+#endif
+
+int main(void) {
+    std::cout << greet() << std::endl;
+    return 0;
+}
@@ -0,0 +1,63 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class PublicOptionsSuite(TestSuite):
+    def TestPublicOptionsPropagated(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-DBASE_INTERNAL .*-DUSE_BASE=1 .*name\.cpp"
+            ).stdoutMustMatch(
+                r"-DGREET_API=1 .*-DUSE_BASE=1 .*greet\.cpp"
+            ).stdoutMustMatch(
+                r"-DUSE_BASE=1 .*main\.cpp"
+            ).stdoutMustMatch(
+                r"-DGREET_API=1 .*main\.cpp"
+            ).stdoutMustNotMatch(
+                r"-DBASE_INTERNAL .*main\.cpp"
+            ).stdoutMustMatch(
+                r"-o \S*hello_exe .*-pthread"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestPrivateLinkOptionsNotPropagated(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "sources/greet/bs_component.lua",
+                'component:CPP():AddLinkOptions "-Wl,-soname,libgreet_private.so"\n'
+                'component:CPP():AddPublicLinkOptions "-Wl,-z,now"\n',
+            )
+            self.runBS(["build", "--build-upstream", "--verbose"]).mustBeOk().stdoutMustMatch(
+                r"-o \S*libgreet_lib\.so .*-Wl,-soname,libgreet_private\.so"
+            ).stdoutMustMatch(
+                r"-o \S*hello_exe .*-Wl,-z,now"
+            ).stdoutMustNotMatch(
+                r"-o \S*hello_exe .*-Wl,-soname"
+            )
+
+    def TestPublicChangeRebuildsDependents(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.appendFile(
+                "sources/base/bs_component.lua",
+                'component:CPP():AddPublicDefines "BASE_LEVEL=2"\n',
+            )
+            self.runBS(["build", "--build-upstream"]).mustBeOk().stdoutMustMatch(
+                r"Compiling .*main\.cpp"
+            )
+
+    def TestPackageFiles(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(
+                ["cat", ".build/lib/pkgconfig/base_lib.pc"]
+            ).mustBeOk().stdoutMustContain(
+                "Cflags: -I${includedir} -DUSE_BASE=1\n",
+                "Libs: -L${libdir} -lbase_lib -pthread\n",
+            )
+            self.runCmd(
+                ["cat", ".build/lib/cmake/greet_lib/greet_libConfig.cmake"]
+            ).mustBeOk().stdoutMustContain(
+                'INTERFACE_COMPILE_OPTIONS "-DGREET_API=1"'
+            )
@@ -0,0 +1,16 @@
+-- A project used to check when targets are rebuilt.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+releaseProfile = project:Profile "Release"
+releaseProfile:CPP():AddBuildOptions "-O2"
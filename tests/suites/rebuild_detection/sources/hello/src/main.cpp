@@ -0,0 +1,7 @@
+#include <iostream>
+#include "message.hpp"
+
+int main(void) {
+    std::cout << MESSAGE << std::endl;
+    return 0;
+}
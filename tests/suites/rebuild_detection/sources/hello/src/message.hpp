@@ -0,0 +1,3 @@
+#pragma once
+
+#define MESSAGE "Hello, World!"
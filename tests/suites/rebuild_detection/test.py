@@ -0,0 +1,95 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class RebuildDetectionSuite(TestSuite):
+    def TestNothingToBeDone(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build"]).mustBeOk().stdoutMustNotContain(
+                "Compiling", "Linking"
+            )
+
+    def TestTouchDoesNotRebuild(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.touchFile(
+                "sources/hello/src/main.cpp", "sources/hello/src/message.hpp"
+            )
+            self.runBS(["build"]).mustBeOk().stdoutMustNotContain(
+                "Compiling", "Linking"
+            )
+
+    def TestHeaderChangeRebuilds(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.writeFile(
+                "sources/hello/src/message.hpp",
+                '#pragma once\n\n#define MESSAGE "Hello, Moon!"\n',
+            )
+            self.runBS(["build"]).mustBeOk().stdoutMustContain(
+                "Compiling", "Linking"
+            )
+            self.runCmd(".build/bin/hello_exe").mustBeOk().stdoutMustContain(
+                "Hello, Moon!"
+            )
+
+    def TestProfileChangeRebuilds(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build", "-p", "Release"]).mustBeOk().stdoutMustContain(
+                "Compiling"
+            )
+            self.runBS(["build", "-p", "Release"]).mustBeOk().stdoutMustNotContain(
+                "Compiling"
+            )
+            self.runBS(["build"]).mustBeOk().stdoutMustNotContain("Compiling")
+
+    def TestDeletedObjectRebuilds(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.removeFile(".build/obj/hello_exe/src/main.o")
+            self.runBS(["build"]).mustBeOk().stdoutMustContain(
+                "Compiling", "Linking"
+            )
+
+    def TestFlagsChangeIsExplained(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            # The Release build shares the build directory of the base one
+            self.runBS(
+                ["build", "-p", "Release", "--build-dir", ".build", "--explain"]
+            ).mustBeOk().stdoutMustContain("flags changed")
+            self.runBS(
+                ["build", "-p", "Release", "--build-dir", ".build", "--explain"]
+            ).mustBeOk().stdoutMustNotContain("needs to be rebuilt")
+
+    def TestNoOpBuildDoesNotScanDependencies(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.runBS(["build", "--verbose"]).mustBeOk().stdoutMustNotContain("-MM ")
+
+    def TestNewIncludeIsTracked(self):
+        with self.sandbox() as s:
+            self.runBS(["build"]).mustBeOk()
+            self.writeFile(
+                "sources/hello/src/other.hpp",
+                '#pragma once\n\n#define OTHER "Hello, Mars!"\n',
+            )
+            self.writeFile(
+                "sources/hello/src/main.cpp",
+                '#include <iostream>\n#include "other.hpp"\n\n'
+                "int main(void) {\n"
+                "    std::cout << OTHER << std::endl;\n"
+                "    return 0;\n"
+                "}\n",
+            )
+            self.runBS(["build"]).mustBeOk().stdoutMustContain("Compiling")
+            self.runBS(["build"]).mustBeOk().stdoutMustNotContain("Compiling")
+            self.writeFile(
+                "sources/hello/src/other.hpp",
+                '#pragma once\n\n#define OTHER "Hello, Venus!"\n',
+            )
+            self.runBS(["build"]).mustBeOk().stdoutMustContain("Compiling")
+            self.runCmd(".build/bin/hello_exe").mustBeOk().stdoutMustContain(
+                "Hello, Venus!"
+            )
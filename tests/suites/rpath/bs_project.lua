@@ -0,0 +1,24 @@
+-- A project whose executables find its shared libraries with their run
+-- path.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
+
+noRPathProfile = project:Profile "NoRPath"
+noRPathProfile:CPP():Dialect "CPP17"
+noRPathProfile:RPath "none"
+
+installProfile = project:Profile "Install"
+installProfile:CPP():Dialect "CPP17"
+installProfile:RPath "$ORIGIN/../lib64:/opt/pretty/lib"
@@ -0,0 +1,78 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class RPathSuite(TestSuite):
+    def TestDefaultRPath(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-o \.build/bin/hello_exe .*'-Wl,-rpath,\$ORIGIN/\.\./lib'"
+            ).stdoutMustMatch(
+                r"-o \.build/lib/libgreet_lib\.so .*'-Wl,-rpath,\$ORIGIN'"
+            ).stdoutMustNotMatch(
+                r"-o \.build/lib/libbase_lib\.so .*-rpath"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+            self.runCmd(["mv", ".build", "moved"]).mustBeOk()
+            self.runCmd(["moved/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestProjectOutputDirectories(self):
+        with self.sandbox() as s:
+            self.appendFile(
+                "bs_project.lua",
+                'project:BinDirectory "usr/bin"\nproject:LibDirectory "usr/lib64"\n',
+            )
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-o \.build/usr/bin/hello_exe .*'-Wl,-rpath,\$ORIGIN/\.\./lib64'"
+            )
+            self.runCmd([".build/usr/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestStaticLibrary(self):
+        with self.sandbox() as s:
+            self.appendFile("sources/greet/bs_component.lua", 'component:Linkage "static"\n')
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustNotMatch(r"ar rcs .*-rpath")
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk()
+
+    def TestDisabledRPath(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "NoRPath", "--verbose"]
+            ).mustBeOk().stdoutMustNotContain("-rpath")
+            self.runCmd([".build/NoRPath/bin/hello_exe"]).mustBeNOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/NoRPath/lib", ".build/NoRPath/bin/hello_exe"]
+            ).mustBeOk()
+
+    def TestOverriddenRPath(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Install", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(
+                r"-o \.build/Install/bin/hello_exe .*'-Wl,-rpath,\$ORIGIN/\.\./lib64' -Wl,-rpath,/opt/pretty/lib"
+            ).stdoutMustMatch(
+                r"-o \.build/Install/lib/libbase_lib\.so .*'-Wl,-rpath,\$ORIGIN/\.\./lib64'"
+            )
+            self.runCmd(
+                ["readelf", "-d", ".build/Install/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain("$ORIGIN/../lib64:/opt/pretty/lib")
+
+    def TestRPathChangeRelinks(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.appendFile("bs_project.lua", 'project:RPath "/opt/pretty/lib"\n')
+            self.runBS(
+                ["build", "--build-upstream", "--explain"]
+            ).mustBeOk().stdoutMustMatch(
+                r"Explain:.* \.build/bin/hello_exe needs to be rebuilt: flags changed"
+            )
@@ -0,0 +1,14 @@
+#include <iostream>
+#include <string>
+#include <greet/greet.hpp>
+
+int main(int argc, char** argv) {
+    std::cout << greet() << std::endl;
+    for (int i = 1; i < argc; i++) {
+        std::cout << "arg: " << argv[i] << std::endl;
+        if (std::string(argv[i]) == "fail") {
+            return 3;
+        }
+    }
+    return 0;
+}
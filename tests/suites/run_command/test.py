@@ -0,0 +1,33 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class RunCommandSuite(TestSuite):
+    def TestRunDefaultTarget(self):
+        with self.sandbox() as s:
+            self.runBS(["run"]).mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            ).stdoutMustNotContain("arg:")
+
+    def TestRunWithArguments(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["run", "hello_exe", "--", "first", "second arg", "--verbose"]
+            ).mustBeOk().stdoutMustContain(
+                "arg: first\narg: second arg\narg: --verbose\n"
+            )
+
+    def TestExitCodeIsForwarded(self):
+        with self.sandbox() as s:
+            self.runBS(["run", "hello_exe", "--", "fail"]).returnCodeMustBe(
+                3
+            ).stdoutMustContain("arg: fail")
+
+    def TestRunLibrary(self):
+        with self.sandbox() as s:
+            self.runBS(["run", "greet_lib"]).mustBeNOk().stdoutMustContain(
+                "Component 'greet_lib' is not an executable"
+            )
+
+    def TestArgumentsOnlyForRun(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--", "first"]).mustBeNOk()
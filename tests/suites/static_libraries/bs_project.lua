@@ -0,0 +1,17 @@
+-- A static library used by a shared library, itself used by an
+-- executable. The Static profile builds every library statically.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+staticProfile = project:Profile "Static"
+staticProfile:Linkage "static"
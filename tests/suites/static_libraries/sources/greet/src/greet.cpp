@@ -0,0 +1,6 @@
+#include <base/name.hpp>
+#include <greet/greet.hpp>
+
+std::string greet() {
+    return "Hello, " + name() + "!";
+}
@@ -0,0 +1,54 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class StaticLibrariesSuite(TestSuite):
+    def TestStaticIntoShared(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustContain(
+                "ar rcs .build/lib/libbase_lib.a"
+            ).stdoutMustMatch(
+                r"-o \.build/lib/libgreet_lib\.so.*-l:libbase_lib\.a"
+            )
+            self.runCmd(["test", "-f", ".build/lib/libbase_lib.a"]).mustBeOk()
+            self.runCmd(["test", "-f", ".build/lib/libbase_lib.so"]).mustBeNOk()
+            self.runCmd(
+                ["env", "LD_LIBRARY_PATH=.build/lib", ".build/bin/hello_exe"]
+            ).mustBeOk().stdoutMustContain("Hello, World!")
+
+    def TestStaticProfile(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Static", "--verbose"]
+            ).mustBeOk().stdoutMustContain(
+                "ar rcs .build/Static/lib/libgreet_lib.a"
+            ).stdoutMustMatch(
+                r"-o \.build/Static/bin/hello_exe.*-l:libgreet_lib\.a -l:libbase_lib\.a"
+            )
+            self.runCmd(".build/Static/bin/hello_exe").mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestNothingToBeDone(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(
+                ["build", "--build-upstream"]
+            ).mustBeOk().stdoutMustNotContain("Compiling", "Linking", "Archiving")
+
+    def TestRelinkOnStaticLibraryChange(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream", "-p", "Static"]).mustBeOk()
+            self.writeFile(
+                "sources/base/src/name.cpp",
+                '#include <base/name.hpp>\n\nstd::string name() {\n    return "Moon";\n}\n',
+            )
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Static"]
+            ).mustBeOk().stdoutMustMatch(
+                r"Archiving .*\.build/Static/lib/libbase_lib\.a"
+            ).stdoutMustMatch(r"Linking .*\.build/Static/bin/hello_exe")
+            self.runCmd(".build/Static/bin/hello_exe").mustBeOk().stdoutMustContain(
+                "Hello, Moon!"
+            )
@@ -0,0 +1,18 @@
+#include <chrono>
+#include <cstdlib>
+#include <iostream>
+#include <thread>
+#include <greet/greet.hpp>
+
+// Fails or hangs on demand, driven by the environment.
+int main(void) {
+    if (std::getenv("TEST_ENV_SLOW") != nullptr) {
+        std::this_thread::sleep_for(std::chrono::seconds(30));
+    }
+    if (std::getenv("TEST_ENV_FAIL") != nullptr) {
+        std::cout << "failing on demand" << std::endl;
+        return 1;
+    }
+    std::cout << greet() << std::endl;
+    return 0;
+}
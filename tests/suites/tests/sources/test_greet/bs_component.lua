@@ -0,0 +1,9 @@
+components = require "components"
+
+component = components:NewComponent "test_greet"
+
+component:Type       "test"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:Requires "greet_lib"
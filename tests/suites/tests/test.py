@@ -0,0 +1,59 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class TestComponentsSuite(TestSuite):
+    def TestNotBuiltByDefault(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build/bin/hello_exe"]).mustBeOk()
+            self.runCmd(["test", "-e", ".build/test/test_greet"]).mustBeNOk()
+
+    def TestRunAll(self):
+        with self.sandbox() as s:
+            self.runBS(["test"]).mustBeOk().stdoutMustMatch(
+                r"PASS.* test_greet"
+            ).stdoutMustMatch(r"PASS.* test_env").stdoutMustContain(
+                "2/2 tests passed"
+            )
+            self.runCmd(["test", "-e", ".build/bin/hello_exe"]).mustBeNOk()
+
+    def TestFailure(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["test"], env={"TEST_ENV_FAIL": "1"}
+            ).mustBeNOk().stdoutMustMatch(
+                r"FAIL.* test_env"
+            ).stdoutMustContain(
+                "failing on demand", "1/2 tests passed"
+            )
+
+    def TestPattern(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["test", "*greet"], env={"TEST_ENV_FAIL": "1"}
+            ).mustBeOk().stdoutMustContain(
+                "1/1 tests passed"
+            ).stdoutMustNotContain("test_env")
+            self.runBS(["test", "nothing*"]).mustBeNOk().stdoutMustContain(
+                "No test component matching 'nothing*'"
+            )
+
+    def TestTimeout(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["test", "test_env", "-t", "1"], env={"TEST_ENV_SLOW": "1"}
+            ).mustBeNOk().stdoutMustMatch(r"TIMEOUT.* test_env").stdoutMustContain(
+                "timed out after 1s"
+            )
+
+    def TestJUnitReport(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["test", "--junit", "report.xml"], env={"TEST_ENV_FAIL": "1"}
+            ).mustBeNOk()
+            self.runCmd(["cat", "report.xml"]).mustBeOk().stdoutMustContain(
+                '<testsuite name="My Tested Project" tests="2" failures="1"',
+                '<testcase name="test_greet" classname="My Tested Project"',
+                '<failure message="exit status 1" type="FAIL">',
+                "failing on demand",
+            )
@@ -0,0 +1,23 @@
+-- A project built with several toolchains. The tools directory holds
+-- stand-ins for the clang and cross compilers.
+version "0.1.0"
+
+project = require "project"
+
+project:Name    "My Pretty Project"
+project:Version "0.0.1"
+
+project:Languages     "CPP"     -- Enables C++ compilation
+
+project:AddSources "sources/"
+
+project:DefaultTarget "hello_exe"
+
+project:CPP():Dialect "CPP17"
+
+clangProfile = project:Profile "Clang"
+clangProfile:Toolchain "clang"
+
+project:Platforms "Arm"
+armPlatform = project:Platform "Arm"
+armPlatform:ToolchainPrefix "aarch64-linux-gnu-"
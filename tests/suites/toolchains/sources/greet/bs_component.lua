@@ -0,0 +1,11 @@
+components = require "components"
+
+component = components:NewComponent "greet_lib"
+
+component:Type       "static"
+component:Languages  "CPP"
+component:AddSources "src/"
+
+component:ExportedHeaders {
+  ["export/[DIRS]/*.hpp"] = "greet/[DIRS]/*.hpp"
+}
@@ -0,0 +1,5 @@
+#include <greet/greet.hpp>
+
+std::string greet() {
+    return "Hello, World!";
+}
@@ -0,0 +1,68 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class ToolchainsSuite(TestSuite):
+    def TestDefaultToolchain(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"]
+            ).mustBeOk().stdoutMustMatch(r"(^|\n)g\+\+ -std=c\+\+17 ")
+
+    def TestProjectToolchain(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Clang", "--verbose"],
+                env={"PATH": self.pathWith("tools")},
+            ).mustBeOk().stdoutMustMatch(
+                r"(^|\n)clang\+\+ .*-c sources/hello/src/main.cpp"
+            ).stdoutMustMatch(r"(^|\n)clang\+\+ .*-o \.build/Clang/bin/hello_exe")
+            self.runCmd(".build/Clang/bin/hello_exe").mustBeOk().stdoutMustContain(
+                "Hello, World!"
+            )
+
+    def TestPlatformCrossPrefix(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-P", "Arm", "--verbose"],
+                env={"PATH": self.pathWith("tools")},
+            ).mustBeOk().stdoutMustMatch(
+                r"(^|\n)aarch64-linux-gnu-g\+\+ -std=c\+\+17 "
+            ).stdoutMustMatch(r"(^|\n)aarch64-linux-gnu-ar rcs ")
+
+    def TestEnvironmentCompiler(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "--verbose"],
+                env={
+                    "PATH": self.pathWith("tools"),
+                    "CXX": "aarch64-linux-gnu-g++",
+                },
+            ).mustBeOk().stdoutMustMatch(
+                r"(^|\n)aarch64-linux-gnu-g\+\+ -std=c\+\+17 "
+            ).stdoutMustMatch(r"(^|\n)aarch64-linux-gnu-ar rcs ")
+
+    def TestEnvironmentCompilersMismatch(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream"],
+                env={"PATH": self.pathWith("tools"), "CC": "clang", "CXX": "g++"},
+            ).mustBeNOk().stderrMustContain(
+                "The compilers CC='clang' and CXX='g++' belong to different toolchains"
+            )
+
+    def TestEnvironmentOverridesProfile(self):
+        with self.sandbox() as s:
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Clang", "--verbose"],
+                env={"CXX": "g++"},
+            ).mustBeOk().stdoutMustMatch(
+                r"(^|\n)g\+\+ .*-c sources/hello/src/main.cpp"
+            )
+
+    def TestToolchainChangeRebuilds(self):
+        with self.sandbox() as s:
+            self.runBS(["build", "--build-upstream"]).mustBeOk()
+            self.runBS(
+                ["build", "--build-upstream", "-p", "Clang"],
+                env={"PATH": self.pathWith("tools")},
+            ).mustBeOk().stdoutMustContain("Compiling")
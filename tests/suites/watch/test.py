@@ -0,0 +1,100 @@
+from test_suite import TestSuite, assertReturnOk
+
+
+class WatchSuite(TestSuite):
+    WATCHING = "Watching for changes"
+
+    def watch(self):
+        return self.runBSInBackground(["build", "--build-upstream", "--watch"])
+
+    def edit(self, filename, content, append=True):
+        # Let the modification time of the file change
+        self.sleep(1)
+        if append:
+            self.appendFile(filename, content)
+        else:
+            self.writeFile(filename, content)
+
+    def TestRebuildChangedComponent(self):
+        with self.sandbox() as s, self.watch() as w:
+            w.waitForOutput(self.WATCHING)
+            self.edit("sources/hello/src/main.cpp", "// changed\n")
+            w.waitForOutput(self.WATCHING, 2).stdoutMustMatch(
+                r"Compiling .*main\.cpp"
+            ).stdoutMustNotContain("greet.cpp", "name.cpp", "'greet_lib'")
+
+    def TestRebuildDependents(self):
+        with self.sandbox() as s, self.watch() as w:
+            w.waitForOutput(self.WATCHING)
+            self.edit(
+                "sources/base/src/name.cpp",
+                '#include <base/name.hpp>\n\nstd::string name() {\n    return "Moon";\n}\n',
+                append=False,
+            )
+            w.waitForOutput(self.WATCHING, 2).stdoutMustMatch(
+                r"Compiling .*name\.cpp"
+            ).stdoutMustMatch(r"Linking .*libgreet_lib\.so").stdoutMustMatch(
+                r"Linking .*hello_exe"
+            )
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, Moon!"
+            )
+
+    def TestChangeDuringBuild(self):
+        with self.sandbox() as s, self.runBSInBackground(
+            ["build", "--build-upstream", "--watch"], env={"CXX": "./slow-g++"}
+        ) as w:
+            w.waitForOutput(self.WATCHING)
+            self.writeFile("slow", "")
+            self.edit("sources/hello/src/main.cpp", "// changed\n")
+            # name.cpp, greet.cpp and main.cpp were compiled by the first
+            # build
+            w.waitForOutput("Compiling", 4)
+            self.edit("sources/greet/src/greet.cpp", "// changed\n")
+            w.waitForOutput(self.WATCHING, 3).stdoutMustMatch(
+                r"Compiling .*greet\.cpp"
+            )
+
+    def TestExportedHeaderChange(self):
+        with self.sandbox() as s, self.watch() as w:
+            w.waitForOutput(self.WATCHING)
+            self.edit("sources/greet/export/greet.hpp", "// changed\n")
+            w.waitForOutput(self.WATCHING, 2).stdoutMustMatch(
+                r"Compiling .*greet\.cpp"
+            ).stdoutMustMatch(r"Compiling .*main\.cpp").stdoutMustNotContain(
+                "name.cpp"
+            )
+
+    def TestConfigurationChange(self):
+        with self.sandbox() as s, self.watch() as w:
+            w.waitForOutput(self.WATCHING)
+            self.edit(
+                "sources/hello/bs_component.lua",
+                'component:CPP():AddBuildOptions "-DCHANGED"\n',
+            )
+            w.waitForOutput(self.WATCHING, 2).stdoutMustContain(
+                "Configuration changed"
+            ).stdoutMustMatch(r"Compiling .*main\.cpp")
+            self.edit("sources/hello/bs_component.lua", "this is not lua\n")
+            w.waitForOutput(self.WATCHING, 3).stdoutMustContain("Error")
+
+    def TestBuildFailureKeepsWatching(self):
+        with self.sandbox() as s, self.watch() as w:
+            w.waitForOutput(self.WATCHING)
+            self.edit("sources/hello/src/main.cpp", "syntax error\n")
+            w.waitForOutput(self.WATCHING, 2).stdoutMustContain(
+                "Failed to build component 'hello_exe'"
+            )
+            self.edit(
+                "sources/hello/src/main.cpp",
+                "#include <iostream>\n#include <greet/greet.hpp>\n\n"
+                "int main(void) {\n"
+                '    std::cout << greet() << " again" << std::endl;\n'
+                "    return 0;\n"
+                "}\n",
+                append=False,
+            )
+            w.waitForOutput(self.WATCHING, 3)
+            self.runCmd([".build/bin/hello_exe"]).mustBeOk().stdoutMustContain(
+                "Hello, World! again"
+            )
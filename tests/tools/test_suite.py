@@ -6,6 +6,9 @@ from colorama import Fore, Style
 from tools.test_assert import AssertError, Asserter
 import shellescape
 import re
+import time
+import json
+import signal
 
 
 def assertReturnOk(res):
@@ -31,6 +34,9 @@ class CompletedProcessWrapper:
     def __init__(self, res):
         self.__res = res
 
+    def stdout(self):
+        return self.__res.stdout.decode("utf-8")
+
     def mustBeOk(self):
         print(".", end="", flush=True)
         if self.__res.returncode != 0:
@@ -54,6 +60,19 @@ class CompletedProcessWrapper:
             )
         return self
 
+    def returnCodeMustBe(self, code):
+        print(".", end="", flush=True)
+        if self.__res.returncode != code:
+            raise AssertError(
+                "Execution returned error code {} where {} was expected\nstdout:\n{}\nstderr:\n{}".format(
+                    self.__res.returncode,
+                    code,
+                    self.__res.stdout.decode("utf-8"),
+                    self.__res.stderr.decode("utf-8"),
+                )
+            )
+        return self
+
     def stderrMustContain(self, *cs):
         print(".", end="", flush=True)
         for c in cs:
@@ -109,6 +128,85 @@ class CompletedProcessWrapper:
                 )
         return self
 
+    def events(self):
+        """Returns the JSON events printed one per line on stdout."""
+        events = []
+        for line in self.__res.stdout.decode("utf-8").splitlines():
+            try:
+                events.append(json.loads(line))
+            except ValueError:
+                raise AssertError(
+                    'Line "{}" is not a JSON object in:\n{}'.format(
+                        line, self.__res.stdout.decode("utf-8")
+                    )
+                )
+        return events
+
+
+class BackgroundProcessWrapper:
+    """A process running while the test goes on, its stdout and stderr are
+    kept to be checked"""
+
+    def __init__(self, options, env=None):
+        self.__log = tempfile.TemporaryFile()
+        self.__process = subprocess.Popen(
+            options, stdout=self.__log, stderr=subprocess.STDOUT, env=env
+        )
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, exception_type, exception_value, exception_traceback):
+        self.stop()
+
+    def output(self):
+        self.__log.seek(0)
+        return self.__log.read().decode("utf-8")
+
+    def waitForOutput(self, text, count=1, timeout=30):
+        """Waits until text is printed count times, and returns the output
+        printed between its previous and its count-th occurrence"""
+        deadline = time.time() + timeout
+        while self.output().count(text) < count:
+            if self.__process.poll() is not None:
+                raise AssertError(
+                    'Process exited before printing "{}":\n{}'.format(
+                        text, self.output()
+                    )
+                )
+            if time.time() > deadline:
+                raise AssertError(
+                    'Timeout waiting for "{}":\n{}'.format(text, self.output())
+                )
+            time.sleep(0.1)
+        print(".", end="", flush=True)
+        out = self.output().split(text)[count - 1]
+        return CompletedProcessWrapper(
+            subprocess.CompletedProcess([], 0, out.encode("utf-8"), b"")
+        )
+
+    def interrupt(self, timeout=10):
+        """Interrupts the process as Ctrl-C does, and returns its result
+        once it exited"""
+        self.__process.send_signal(signal.SIGINT)
+        try:
+            returncode = self.__process.wait(timeout)
+        except subprocess.TimeoutExpired:
+            raise AssertError(
+                "Process did not exit once interrupted:\n{}".format(self.output())
+            )
+        print(".", end="", flush=True)
+        return CompletedProcessWrapper(
+            subprocess.CompletedProcess(
+                [], returncode, self.output().encode("utf-8"), b""
+            )
+        )
+
+    def stop(self):
+        self.__process.terminate()
+        self.__process.wait()
+        self.__log.close()
+
 
 class TestSuite(Asserter):
     def __init__(self, name: str, d: str, bspath: str):
@@ -144,7 +242,7 @@ class TestSuite(Asserter):
         print(" " + getPass())
         return True
 
-    def runBS(self, options):
+    def runBS(self, options, env=None):
         if self.__verbose:
             print(
                 "{}Running command{}: {}".format(
@@ -153,10 +251,13 @@ class TestSuite(Asserter):
                     " ".join([shellescape.quote(o) for o in options]),
                 )
             )
+        if env is not None:
+            env = dict(os.environ, **env)
         res = subprocess.run(
             [self.BSPath()] + options,
             stdout=subprocess.PIPE,
             stderr=subprocess.PIPE,
+            env=env,
         )
         if self.__verbose:
             if len(res.stdout.decode("utf-8")) > 0:
@@ -167,10 +268,51 @@ class TestSuite(Asserter):
                 print(res.stderr.decode("utf-8"))
         return CompletedProcessWrapper(res)
 
+    def sleep(self, seconds):
+        time.sleep(seconds)
+
+    def runBSInBackground(self, options, env=None):
+        if env is not None:
+            env = dict(os.environ, **env)
+        return BackgroundProcessWrapper([self.BSPath()] + options, env=env)
+
+    def eventsOfKind(self, events, kind):
+        return [e for e in events if e["event"] == kind]
+
+    def findEvent(self, events, kind, **fields):
+        print(".", end="", flush=True)
+        for e in self.eventsOfKind(events, kind):
+            if all(e.get(k) == v for k, v in fields.items()):
+                return e
+        raise AssertError(
+            "Could not find a {} event with {} in:\n{}".format(
+                kind, fields, "\n".join(str(e) for e in events)
+            )
+        )
+
+    def readJSON(self, filename):
+        with open(filename) as f:
+            return json.load(f)
+
     def removeFile(self, *filenames):
         for filename in filenames:
             os.remove(filename)
 
+    def touchFile(self, *filenames):
+        for filename in filenames:
+            os.utime(filename)
+
+    def writeFile(self, filename, content):
+        with open(filename, "w") as file:
+            file.write(content)
+
+    def appendFile(self, filename, content):
+        with open(filename, "a") as file:
+            file.write(content)
+
+    def pathWith(self, directory):
+        return os.path.abspath(directory) + os.pathsep + os.environ["PATH"]
+
     def runCmd(self, options):
         res = subprocess.run(
             options,